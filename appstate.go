@@ -13,6 +13,8 @@ import (
 	"fmt"
 	"time"
 
+	"google.golang.org/protobuf/proto"
+
 	"go.mau.fi/whatsmeow/appstate"
 	waBinary "go.mau.fi/whatsmeow/binary"
 	"go.mau.fi/whatsmeow/proto/waE2E"
@@ -77,8 +79,8 @@ func (cli *Client) FetchAppState(ctx context.Context, name appstate.WAPatchName,
 				return fmt.Errorf("failed to update contact store with data from snapshot: %v", err)
 			}
 		}
-		for _, mutation := range mutations {
-			cli.dispatchAppState(ctx, mutation, fullSync, cli.EmitAppStateEventsOnFullSync)
+		for _, evt := range cli.applyAppStateMutations(ctx, mutations, fullSync, cli.EmitAppStateEventsOnFullSync) {
+			cli.dispatchEvent(evt)
 		}
 	}
 	if fullSync {
@@ -109,7 +111,39 @@ func (cli *Client) filterContacts(mutations []appstate.Mutation) ([]appstate.Mut
 	return filteredMutations, contacts
 }
 
-func (cli *Client) dispatchAppState(ctx context.Context, mutation appstate.Mutation, fullSync bool, emitOnFullSync bool) {
+// applyAppStateMutations updates the device store with the given mutations in a single transaction
+// and returns the events to dispatch. Events are only dispatched after the transaction is committed,
+// so that event handlers accessing the store don't block on it.
+func (cli *Client) applyAppStateMutations(ctx context.Context, mutations []appstate.Mutation, fullSync bool, emitOnFullSync bool) []any {
+	var evts []any
+	err := cli.Store.AppState.DoAppStateTxn(ctx, func(ctx context.Context) error {
+		evts = evts[:0]
+		for _, mutation := range mutations {
+			mutationEvts, err := cli.applyAppStateMutation(ctx, mutation, fullSync, emitOnFullSync)
+			if err != nil {
+				return err
+			}
+			evts = append(evts, mutationEvts...)
+		}
+		return nil
+	})
+	if err != nil {
+		// A failed statement aborts the whole transaction on Postgres, so fall back to storing the mutations
+		// one by one to make sure a single broken mutation doesn't prevent storing the others.
+		cli.Log.Warnf("Failed to apply app state mutations in a transaction, retrying individually: %v", err)
+		evts = evts[:0]
+		for _, mutation := range mutations {
+			mutationEvts, err := cli.applyAppStateMutation(ctx, mutation, fullSync, emitOnFullSync)
+			if err != nil {
+				cli.Log.Errorf("Failed to update device store after app state mutation: %v", err)
+			}
+			evts = append(evts, mutationEvts...)
+		}
+	}
+	return evts
+}
+
+func (cli *Client) applyAppStateMutation(ctx context.Context, mutation appstate.Mutation, fullSync bool, emitOnFullSync bool) (evts []any, storeUpdateError error) {
 	dispatchEvts := !fullSync || emitOnFullSync
 
 	if mutation.Operation != waServerSync.SyncdMutation_SET {
@@ -117,7 +151,7 @@ func (cli *Client) dispatchAppState(ctx context.Context, mutation appstate.Mutat
 	}
 
 	if dispatchEvts {
-		cli.dispatchEvent(&events.AppState{Index: mutation.Index, SyncActionValue: mutation.Action})
+		evts = append(evts, &events.AppState{Index: mutation.Index, SyncActionValue: mutation.Action})
 	}
 
 	var jid types.JID
@@ -126,7 +160,6 @@ func (cli *Client) dispatchAppState(ctx context.Context, mutation appstate.Mutat
 	}
 	ts := time.UnixMilli(mutation.Action.GetTimestamp())
 
-	var storeUpdateError error
 	var eventToDispatch interface{}
 	switch mutation.Index[0] {
 	case appstate.IndexMute:
@@ -212,17 +245,32 @@ func (cli *Client) dispatchAppState(ctx context.Context, mutation appstate.Mutat
 			Action:       mutation.Action.GetPushNameSetting(),
 			FromFullSync: fullSync,
 		}
+		hadPushName := len(cli.Store.PushName) > 0
 		cli.Store.PushName = mutation.Action.GetPushNameSetting().GetName()
 		err := cli.Store.Save(ctx)
 		if err != nil {
 			cli.Log.Errorf("Failed to save device store after updating push name: %v", err)
 		}
+		if cli.AutoSendPresence && !hadPushName && len(cli.Store.PushName) > 0 {
+			go func() {
+				err := cli.SendPresence(types.PresenceAvailable)
+				if err != nil {
+					cli.Log.Warnf("Failed to send automatic presence after receiving push name: %v", err)
+				}
+			}()
+		}
 	case appstate.IndexSettingUnarchiveChats:
 		eventToDispatch = &events.UnarchiveChatsSetting{
 			Timestamp:    ts,
 			Action:       mutation.Action.GetUnarchiveChatsSetting(),
 			FromFullSync: fullSync,
 		}
+	case appstate.IndexSettingLocale:
+		eventToDispatch = &events.LocaleSetting{
+			Timestamp:    ts,
+			Action:       mutation.Action.GetLocaleSetting(),
+			FromFullSync: fullSync,
+		}
 	case appstate.IndexUserStatusMute:
 		eventToDispatch = &events.UserStatusMute{
 			JID:          jid,
@@ -265,13 +313,18 @@ func (cli *Client) dispatchAppState(ctx context.Context, mutation appstate.Mutat
 			Action:       act,
 			FromFullSync: fullSync,
 		}
-	}
-	if storeUpdateError != nil {
-		cli.Log.Errorf("Failed to update device store after app state mutation: %v", storeUpdateError)
+	default:
+		eventToDispatch = &events.RawAppStateMutation{
+			Index:        mutation.Index,
+			Timestamp:    ts,
+			Action:       mutation.Action,
+			FromFullSync: fullSync,
+		}
 	}
 	if dispatchEvts && eventToDispatch != nil {
-		cli.dispatchEvent(eventToDispatch)
+		evts = append(evts, eventToDispatch)
 	}
+	return
 }
 
 func (cli *Client) downloadExternalAppStateBlob(ctx context.Context, ref *waServerSync.ExternalBlobReference) ([]byte, error) {
@@ -323,6 +376,31 @@ func (cli *Client) requestMissingAppStateKeys(ctx context.Context, patches *apps
 }
 
 func (cli *Client) requestAppStateKeys(ctx context.Context, rawKeyIDs [][]byte) {
+	err := cli.sendAppStateKeyRequest(ctx, rawKeyIDs)
+	if err != nil {
+		cli.Log.Warnf("Failed to send app state key request: %v", err)
+	}
+}
+
+// RequestAppStateKeys asks the primary device to send the app state sync keys with the given IDs.
+//
+// Missing keys are requested automatically when app state patches can't be decrypted, so this is only
+// needed for manually recovering from errors. An events.AppStateSyncKeysReceived is dispatched when the
+// keys arrive, after which all app state types are resynced.
+func (cli *Client) RequestAppStateKeys(ctx context.Context, keyIDs [][]byte) error {
+	if cli == nil {
+		return ErrClientIsNil
+	}
+	now := time.Now()
+	cli.appStateKeyRequestsLock.Lock()
+	for _, keyID := range keyIDs {
+		cli.appStateKeyRequests[hex.EncodeToString(keyID)] = now
+	}
+	cli.appStateKeyRequestsLock.Unlock()
+	return cli.sendAppStateKeyRequest(ctx, keyIDs)
+}
+
+func (cli *Client) sendAppStateKeyRequest(ctx context.Context, rawKeyIDs [][]byte) error {
 	keyIDs := make([]*waE2E.AppStateSyncKeyId, len(rawKeyIDs))
 	debugKeyIDs := make([]string, len(rawKeyIDs))
 	for i, keyID := range rawKeyIDs {
@@ -338,19 +416,71 @@ func (cli *Client) requestAppStateKeys(ctx context.Context, rawKeyIDs [][]byte)
 		},
 	}
 	ownID := cli.getOwnID().ToNonAD()
-	if ownID.IsEmpty() || len(debugKeyIDs) == 0 {
-		return
+	if ownID.IsEmpty() {
+		return ErrNotLoggedIn
+	} else if len(debugKeyIDs) == 0 {
+		return nil
 	}
 	cli.Log.Infof("Sending key request for app state keys %+v", debugKeyIDs)
 	_, err := cli.SendMessage(ctx, ownID, msg, SendRequestExtra{Peer: true})
-	if err != nil {
-		cli.Log.Warnf("Failed to send app state key request: %v", err)
+	return err
+}
+
+// BuildAppStateSyncKeyShare builds a peer message that shares the given app state sync keys with other devices.
+// The keys are read from the local key store. The built message can be sent using Client.SendPeerMessage.
+func (cli *Client) BuildAppStateSyncKeyShare(ctx context.Context, keyIDs [][]byte) (*waE2E.Message, error) {
+	keys := make([]*waE2E.AppStateSyncKey, 0, len(keyIDs))
+	for _, keyID := range keyIDs {
+		key, err := cli.Store.AppStateKeys.GetAppStateSyncKey(ctx, keyID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get app state key %X: %w", keyID, err)
+		} else if key == nil {
+			return nil, fmt.Errorf("app state key %X not found", keyID)
+		}
+		var fingerprint waE2E.AppStateSyncKeyFingerprint
+		err = proto.Unmarshal(key.Fingerprint, &fingerprint)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal fingerprint of app state key %X: %w", keyID, err)
+		}
+		keys = append(keys, &waE2E.AppStateSyncKey{
+			KeyID: &waE2E.AppStateSyncKeyId{KeyID: keyID},
+			KeyData: &waE2E.AppStateSyncKeyData{
+				KeyData:     key.Data,
+				Fingerprint: &fingerprint,
+				Timestamp:   proto.Int64(key.Timestamp),
+			},
+		})
 	}
+	return &waE2E.Message{
+		ProtocolMessage: &waE2E.ProtocolMessage{
+			Type: waE2E.ProtocolMessage_APP_STATE_SYNC_KEY_SHARE.Enum(),
+			AppStateSyncKeyShare: &waE2E.AppStateSyncKeyShare{
+				Keys: keys,
+			},
+		},
+	}, nil
 }
 
+// SendAppStatePatch builds a patch from the given raw mutations and sends it with SendAppState.
+//
+// This can be used to sync mutation types that don't have a Build method in the appstate package.
+// The mutations are encrypted with the latest app state key, and the state hash is updated automatically.
+func (cli *Client) SendAppStatePatch(ctx context.Context, collection appstate.WAPatchName, mutations ...appstate.MutationInfo) error {
+	return cli.SendAppState(ctx, appstate.PatchInfo{
+		Type:      collection,
+		Mutations: mutations,
+	})
+}
+
+// maxAppStateConflictRetries is the number of times SendAppState will resync and retry after a version conflict.
+const maxAppStateConflictRetries = 3
+
 // SendAppState sends the given app state patch, then resyncs that app state type from the server
 // to update local caches and send events for the updates.
 //
+// If the server rejects the patch because the local app state is outdated, the app state is resynced
+// and the patch is sent again, up to three times.
+//
 // You can use the Build methods in the appstate package to build the parameter for this method, e.g.
 //
 //	cli.SendAppState(ctx, appstate.BuildMute(targetJID, true, 24 * time.Hour))
@@ -358,6 +488,24 @@ func (cli *Client) SendAppState(ctx context.Context, patch appstate.PatchInfo) e
 	if cli == nil {
 		return ErrClientIsNil
 	}
+	for attempt := 1; ; attempt++ {
+		err := cli.sendAppStatePatch(ctx, patch)
+		if errors.Is(err, ErrAppStateVersionConflict) && attempt <= maxAppStateConflictRetries {
+			cli.Log.Debugf("Got version conflict sending app state %s patch (attempt #%d), resyncing and retrying", patch.Type, attempt)
+			err = cli.FetchAppState(ctx, patch.Type, false, false)
+			if err != nil {
+				return fmt.Errorf("failed to resync app state after version conflict: %w", err)
+			}
+			continue
+		} else if err != nil {
+			return err
+		}
+		break
+	}
+	return cli.FetchAppState(ctx, patch.Type, false, false)
+}
+
+func (cli *Client) sendAppStatePatch(ctx context.Context, patch appstate.PatchInfo) error {
 	version, hash, err := cli.Store.AppState.GetAppStateVersion(ctx, string(patch.Type))
 	if err != nil {
 		return err
@@ -405,9 +553,12 @@ func (cli *Client) SendAppState(ctx context.Context, patch appstate.PatchInfo) e
 	respCollection := resp.GetChildByTag("sync", "collection")
 	respCollectionAttr := respCollection.AttrGetter()
 	if respCollectionAttr.OptionalString("type") == "error" {
-		// TODO parse error properly
+		errNode, _ := respCollection.GetOptionalChildByTag("error")
+		if errNode.AttrGetter().OptionalInt("code") == 409 {
+			return fmt.Errorf("%w (%w): %s", ErrAppStateUpdate, ErrAppStateVersionConflict, respCollection.XMLString())
+		}
+		// TODO parse other errors properly
 		return fmt.Errorf("%w: %s", ErrAppStateUpdate, respCollection.XMLString())
 	}
-
-	return cli.FetchAppState(ctx, patch.Type, false, false)
+	return nil
 }
@@ -24,6 +24,8 @@ type MutationInfo struct {
 	Version int32
 	// Value contains the data for the mutation.
 	Value *waSyncAction.SyncActionValue
+	// Operation is the type of the mutation. The zero value is SET, which adds or replaces the value at the index.
+	Operation waServerSync.SyncdMutation_SyncdOperation
 }
 
 // PatchInfo contains information about a patch to the app state.
@@ -282,11 +284,11 @@ func (proc *Processor) EncodePatch(ctx context.Context, keyID []byte, state Hash
 			return nil, fmt.Errorf("failed to encrypt mutation: %w", err)
 		}
 
-		valueMac := generateContentMAC(waServerSync.SyncdMutation_SET, encryptedContent, keyID, keys.ValueMAC)
+		valueMac := generateContentMAC(mutationInfo.Operation, encryptedContent, keyID, keys.ValueMAC)
 		indexMac := concatAndHMAC(sha256.New, keys.Index, indexBytes)
 
 		mutations = append(mutations, &waServerSync.SyncdMutation{
-			Operation: waServerSync.SyncdMutation_SET.Enum(),
+			Operation: mutationInfo.Operation.Enum(),
 			Record: &waServerSync.SyncdRecord{
 				Index: &waServerSync.SyncdIndex{Blob: indexMac},
 				Value: &waServerSync.SyncdValue{Blob: append(encryptedContent, valueMac...)},
@@ -49,6 +49,7 @@ const (
 	IndexMarkChatAsRead          = "markChatAsRead"
 	IndexSettingPushName         = "setting_pushName"
 	IndexSettingUnarchiveChats   = "setting_unarchiveChats"
+	IndexSettingLocale           = "setting_locale"
 	IndexUserStatusMute          = "userStatusMute"
 	IndexLabelEdit               = "label_edit"
 	IndexLabelAssociationChat    = "label_jid"
@@ -0,0 +1,225 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package archive contains an event handler that exports decrypted messages to an archive, e.g. for backups.
+//
+//	format, err := archive.NewJSONLinesFormat("/path/to/archive")
+//	exporter := archive.NewExporter(cli, format, log)
+//	cli.AddEventHandler(exporter.HandleEvent)
+//	...
+//	exporter.Close()
+//
+// Both real-time messages and messages from history syncs are exported. Media is not downloaded,
+// but each record contains the metadata needed to download it later with Client.DownloadMediaWithPath.
+package archive
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+	waLog "go.mau.fi/whatsmeow/util/log"
+)
+
+// Source specifies where an archived message came from.
+type Source string
+
+const (
+	SourceLive        Source = "live"
+	SourceHistorySync Source = "history_sync"
+)
+
+// MediaRef contains the metadata of a media attachment, which can be used to download it later.
+type MediaRef struct {
+	Type          string `json:"type"`
+	MimeType      string `json:"mime_type,omitempty"`
+	FileName      string `json:"file_name,omitempty"`
+	FileLength    uint64 `json:"file_length,omitempty"`
+	DirectPath    string `json:"direct_path"`
+	MediaKey      []byte `json:"media_key"`
+	FileSHA256    []byte `json:"file_sha256"`
+	FileEncSHA256 []byte `json:"file_enc_sha256"`
+}
+
+// Record is a single archived message.
+type Record struct {
+	ID        types.MessageID `json:"id"`
+	Chat      types.JID       `json:"chat"`
+	Sender    types.JID       `json:"sender"`
+	IsFromMe  bool            `json:"is_from_me"`
+	PushName  string          `json:"push_name,omitempty"`
+	Timestamp time.Time       `json:"timestamp"`
+	Source    Source          `json:"source"`
+
+	// The plain text body or media caption of the message, if any.
+	Text  string    `json:"text,omitempty"`
+	Media *MediaRef `json:"media,omitempty"`
+	// The full message protobuf encoded with protojson.
+	Message json.RawMessage `json:"message,omitempty"`
+}
+
+// Format is an archive format that records are written to.
+type Format interface {
+	WriteRecord(rec *Record) error
+	Close() error
+}
+
+// Exporter writes messages from whatsmeow events into an archive Format.
+type Exporter struct {
+	cli    *whatsmeow.Client
+	format Format
+	log    waLog.Logger
+
+	// If set, only messages for which the filter returns true are exported.
+	Filter func(rec *Record) bool
+
+	lock   sync.Mutex
+	closed bool
+}
+
+// NewExporter creates a new exporter that writes messages to the given format.
+// The client is used for parsing messages in history syncs.
+func NewExporter(cli *whatsmeow.Client, format Format, log waLog.Logger) *Exporter {
+	if log == nil {
+		log = waLog.Noop
+	}
+	return &Exporter{
+		cli:    cli,
+		format: format,
+		log:    log,
+	}
+}
+
+// HandleEvent exports the messages in the given event. It can be passed directly to Client.AddEventHandler.
+func (e *Exporter) HandleEvent(rawEvt any) {
+	switch evt := rawEvt.(type) {
+	case *events.Message:
+		e.export(evt, SourceLive)
+	case *events.HistorySync:
+		for _, conv := range evt.Data.GetConversations() {
+			msgs, err := e.cli.ParseHistorySyncConversation(conv)
+			if err != nil {
+				e.log.Warnf("Failed to parse some messages in history sync conversation %s: %v", conv.GetID(), err)
+			}
+			for _, msg := range msgs {
+				e.export(msg, SourceHistorySync)
+			}
+		}
+	}
+}
+
+// Close closes the underlying archive format. Events received after closing are ignored.
+func (e *Exporter) Close() error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+	return e.format.Close()
+}
+
+func (e *Exporter) export(evt *events.Message, source Source) {
+	rec, err := NewRecord(evt, source)
+	if err != nil {
+		e.log.Errorf("Failed to create archive record for %s in %s: %v", evt.Info.ID, evt.Info.Chat, err)
+		return
+	} else if e.Filter != nil && !e.Filter(rec) {
+		return
+	}
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if e.closed {
+		return
+	}
+	err = e.format.WriteRecord(rec)
+	if err != nil {
+		e.log.Errorf("Failed to write %s in %s to archive: %v", evt.Info.ID, evt.Info.Chat, err)
+	}
+}
+
+// NewRecord converts the given message event into an archive record.
+func NewRecord(evt *events.Message, source Source) (*Record, error) {
+	rec := &Record{
+		ID:        evt.Info.ID,
+		Chat:      evt.Info.Chat,
+		Sender:    evt.Info.Sender,
+		IsFromMe:  evt.Info.IsFromMe,
+		PushName:  evt.Info.PushName,
+		Timestamp: evt.Info.Timestamp,
+		Source:    source,
+		Text:      getText(evt.Message),
+		Media:     getMediaRef(evt.Message),
+	}
+	if evt.Message != nil {
+		var err error
+		rec.Message, err = protojson.Marshal(evt.Message)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal message: %w", err)
+		}
+	}
+	return rec, nil
+}
+
+func getText(msg *waE2E.Message) string {
+	switch {
+	case msg.GetConversation() != "":
+		return msg.GetConversation()
+	case msg.GetExtendedTextMessage() != nil:
+		return msg.GetExtendedTextMessage().GetText()
+	case msg.GetImageMessage() != nil:
+		return msg.GetImageMessage().GetCaption()
+	case msg.GetVideoMessage() != nil:
+		return msg.GetVideoMessage().GetCaption()
+	case msg.GetDocumentMessage() != nil:
+		return msg.GetDocumentMessage().GetCaption()
+	default:
+		return ""
+	}
+}
+
+type archivableMedia interface {
+	whatsmeow.DownloadableMessage
+	GetMimetype() string
+	GetFileLength() uint64
+}
+
+func getMediaRef(msg *waE2E.Message) *MediaRef {
+	var media archivableMedia
+	var mediaType, fileName string
+	switch {
+	case msg.GetImageMessage() != nil:
+		media, mediaType = msg.GetImageMessage(), "image"
+	case msg.GetVideoMessage() != nil:
+		media, mediaType = msg.GetVideoMessage(), "video"
+	case msg.GetAudioMessage() != nil:
+		media, mediaType = msg.GetAudioMessage(), "audio"
+	case msg.GetStickerMessage() != nil:
+		media, mediaType = msg.GetStickerMessage(), "sticker"
+	case msg.GetDocumentMessage() != nil:
+		media, mediaType = msg.GetDocumentMessage(), "document"
+		fileName = msg.GetDocumentMessage().GetFileName()
+	default:
+		return nil
+	}
+	return &MediaRef{
+		Type:          mediaType,
+		MimeType:      media.GetMimetype(),
+		FileName:      fileName,
+		FileLength:    media.GetFileLength(),
+		DirectPath:    media.GetDirectPath(),
+		MediaKey:      media.GetMediaKey(),
+		FileSHA256:    media.GetFileSHA256(),
+		FileEncSHA256: media.GetFileEncSHA256(),
+	}
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package archive
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"go.mau.fi/whatsmeow/types"
+)
+
+// JSONLinesFormat writes records as JSON lines into a separate file for each chat.
+//
+// Files are named after the chat JID (e.g. 123456789@s.whatsapp.net.jsonl) and are appended to,
+// so the same directory can be reused across restarts.
+//
+// At most MaxOpenFiles files are kept open at once. When the limit is reached,
+// the least recently written file is closed, and reopened if it's written to again.
+type JSONLinesFormat struct {
+	// The maximum number of files to keep open. Defaults to DefaultMaxOpenFiles.
+	MaxOpenFiles int
+
+	dir     string
+	files   map[types.JID]*openFile
+	counter uint64
+}
+
+type openFile struct {
+	*os.File
+	lastUsed uint64
+}
+
+// DefaultMaxOpenFiles is the default value for JSONLinesFormat.MaxOpenFiles.
+const DefaultMaxOpenFiles = 64
+
+var _ Format = (*JSONLinesFormat)(nil)
+
+// NewJSONLinesFormat creates a new JSON lines archive in the given directory, creating it if it doesn't exist.
+func NewJSONLinesFormat(dir string) (*JSONLinesFormat, error) {
+	err := os.MkdirAll(dir, 0700)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create archive directory: %w", err)
+	}
+	return &JSONLinesFormat{
+		MaxOpenFiles: DefaultMaxOpenFiles,
+
+		dir:   dir,
+		files: make(map[types.JID]*openFile),
+	}, nil
+}
+
+func (f *JSONLinesFormat) getFile(chat types.JID) (*os.File, error) {
+	chat = chat.ToNonAD()
+	f.counter++
+	file, ok := f.files[chat]
+	if !ok {
+		err := f.evictFiles(max(f.MaxOpenFiles, 1) - 1)
+		if err != nil {
+			return nil, err
+		}
+		osFile, err := os.OpenFile(filepath.Join(f.dir, chat.String()+".jsonl"), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+		if err != nil {
+			return nil, err
+		}
+		file = &openFile{File: osFile}
+		f.files[chat] = file
+	}
+	file.lastUsed = f.counter
+	return file.File, nil
+}
+
+// evictFiles closes the least recently used files until at most limit files are open.
+func (f *JSONLinesFormat) evictFiles(limit int) error {
+	for len(f.files) > limit {
+		var oldestChat types.JID
+		var oldest *openFile
+		for chat, file := range f.files {
+			if oldest == nil || file.lastUsed < oldest.lastUsed {
+				oldestChat, oldest = chat, file
+			}
+		}
+		delete(f.files, oldestChat)
+		if err := oldest.Close(); err != nil {
+			return fmt.Errorf("failed to close archive file of %s: %w", oldestChat, err)
+		}
+	}
+	return nil
+}
+
+// WriteRecord appends the given record to the file of its chat.
+func (f *JSONLinesFormat) WriteRecord(rec *Record) error {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal record: %w", err)
+	}
+	file, err := f.getFile(rec.Chat)
+	if err != nil {
+		return fmt.Errorf("failed to open archive file: %w", err)
+	}
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+// Close closes all open archive files.
+func (f *JSONLinesFormat) Close() error {
+	var errs []error
+	for chat, file := range f.files {
+		if err := file.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close archive file of %s: %w", chat, err))
+		}
+		delete(f.files, chat)
+	}
+	return errors.Join(errs...)
+}
@@ -1,6 +1,8 @@
 package binary
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
 	"io"
 	"strings"
@@ -12,10 +14,39 @@ import (
 type binaryDecoder struct {
 	data  []byte
 	index int
+
+	// If set, data is read from this reader instead of the data slice.
+	src     *bufio.Reader
+	scratch [4]byte
 }
 
 func newDecoder(data []byte) *binaryDecoder {
-	return &binaryDecoder{data, 0}
+	return &binaryDecoder{data: data}
+}
+
+func newStreamDecoder(src io.Reader) *binaryDecoder {
+	return &binaryDecoder{src: bufio.NewReader(src)}
+}
+
+// readSmall reads n bytes (at most 4) into the scratch buffer when streaming,
+// or returns a slice of the data directly otherwise. The returned slice must not be retained.
+func (r *binaryDecoder) readSmall(n int) ([]byte, error) {
+	if r.src != nil {
+		_, err := io.ReadFull(r.src, r.scratch[:n])
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, io.EOF
+		} else if err != nil {
+			return nil, err
+		}
+		r.index += n
+		return r.scratch[:n], nil
+	}
+	if err := r.checkEOS(n); err != nil {
+		return nil, err
+	}
+	ret := r.data[r.index : r.index+n]
+	r.index += n
+	return ret, nil
 }
 
 func (r *binaryDecoder) checkEOS(length int) error {
@@ -27,6 +58,13 @@ func (r *binaryDecoder) checkEOS(length int) error {
 }
 
 func (r *binaryDecoder) readByte() (byte, error) {
+	if r.src != nil {
+		b, err := r.src.ReadByte()
+		if err == nil {
+			r.index++
+		}
+		return b, err
+	}
 	if err := r.checkEOS(1); err != nil {
 		return 0, err
 	}
@@ -38,7 +76,8 @@ func (r *binaryDecoder) readByte() (byte, error) {
 }
 
 func (r *binaryDecoder) readIntN(n int, littleEndian bool) (int, error) {
-	if err := r.checkEOS(n); err != nil {
+	data, err := r.readSmall(n)
+	if err != nil {
 		return 0, err
 	}
 
@@ -51,10 +90,9 @@ func (r *binaryDecoder) readIntN(n int, littleEndian bool) (int, error) {
 		} else {
 			curShift = n - i - 1
 		}
-		ret |= int(r.data[r.index+i]) << uint(curShift*8)
+		ret |= int(data[i]) << uint(curShift*8)
 	}
 
-	r.index += n
 	return ret, nil
 }
 
@@ -67,12 +105,12 @@ func (r *binaryDecoder) readInt16(littleEndian bool) (int, error) {
 }
 
 func (r *binaryDecoder) readInt20() (int, error) {
-	if err := r.checkEOS(3); err != nil {
+	data, err := r.readSmall(3)
+	if err != nil {
 		return 0, err
 	}
 
-	ret := ((int(r.data[r.index]) & 15) << 16) + (int(r.data[r.index+1]) << 8) + int(r.data[r.index+2])
-	r.index += 3
+	ret := ((int(data[0]) & 15) << 16) + (int(data[1]) << 8) + int(data[2])
 	return ret, nil
 }
 
@@ -395,6 +433,17 @@ func (r *binaryDecoder) readBytesOrString(length int, asString bool) (interface{
 }
 
 func (r *binaryDecoder) readRaw(length int) ([]byte, error) {
+	if r.src != nil {
+		// Don't trust the length for allocating the buffer, only allocate as much as there's actually data
+		ret, err := io.ReadAll(io.LimitReader(r.src, int64(length)))
+		if err != nil {
+			return nil, err
+		} else if len(ret) < length {
+			return nil, io.EOF
+		}
+		r.index += length
+		return ret, nil
+	}
 	if err := r.checkEOS(length); err != nil {
 		return nil, err
 	}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	"strconv"
+	"sync"
 
 	"go.mau.fi/whatsmeow/binary/token"
 	"go.mau.fi/whatsmeow/types"
@@ -17,6 +18,28 @@ func newEncoder() *binaryEncoder {
 	return &binaryEncoder{[]byte{0}}
 }
 
+// maxPooledEncoderSize is the maximum buffer capacity that is returned to the encoder pool.
+// Larger buffers (e.g. from history sync or media IQs) are rare, so they're left for the GC.
+const maxPooledEncoderSize = 64 * 1024
+
+var encoderPool = sync.Pool{
+	New: func() any {
+		return &binaryEncoder{data: make([]byte, 0, 1024)}
+	},
+}
+
+func getPooledEncoder() *binaryEncoder {
+	w := encoderPool.Get().(*binaryEncoder)
+	w.data = append(w.data[:0], 0)
+	return w
+}
+
+func putPooledEncoder(w *binaryEncoder) {
+	if cap(w.data) <= maxPooledEncoderSize {
+		encoderPool.Put(w)
+	}
+}
+
 func (w *binaryEncoder) getData() []byte {
 	return w.data
 }
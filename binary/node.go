@@ -8,6 +8,7 @@
 package binary
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 
@@ -121,9 +122,12 @@ func (n *Node) GetChildByTag(tags ...string) Node {
 
 // Marshal encodes an XML element (Node) into WhatsApp's binary XML representation.
 func Marshal(n Node) ([]byte, error) {
-	w := newEncoder()
+	w := getPooledEncoder()
 	w.writeNode(n)
-	return w.getData(), nil
+	// The encoder buffer is reused, so the output must be copied
+	data := bytes.Clone(w.getData())
+	putPooledEncoder(w)
+	return data, nil
 }
 
 // Unmarshal decodes WhatsApp's binary XML representation into a Node.
@@ -0,0 +1,143 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package binary_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+	"testing"
+
+	waBinary "go.mau.fi/whatsmeow/binary"
+	"go.mau.fi/whatsmeow/types"
+)
+
+var benchNode = waBinary.Node{
+	Tag: "message",
+	Attrs: waBinary.Attrs{
+		"id":   "3EB0C431C26A1916F18F",
+		"to":   types.NewJID("1234567890", types.DefaultUserServer),
+		"type": "text",
+		"t":    1700000000,
+	},
+	Content: []waBinary.Node{{
+		Tag:     "enc",
+		Attrs:   waBinary.Attrs{"v": "2", "type": "msg"},
+		Content: bytes.Repeat([]byte{0xAB}, 512),
+	}},
+}
+
+func BenchmarkMarshal(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		_, err := waBinary.Marshal(benchNode)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnmarshal(b *testing.B) {
+	data, err := waBinary.Marshal(benchNode)
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	for b.Loop() {
+		_, err = waBinary.Unmarshal(data[1:])
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnpackCompressed(b *testing.B) {
+	data, err := waBinary.Marshal(benchNode)
+	if err != nil {
+		b.Fatal(err)
+	}
+	var buf bytes.Buffer
+	buf.WriteByte(2)
+	zw := zlib.NewWriter(&buf)
+	_, _ = zw.Write(data[1:])
+	_ = zw.Close()
+	compressed := buf.Bytes()
+	b.ReportAllocs()
+	for b.Loop() {
+		_, err = waBinary.Unpack(compressed)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkUnpackAndUnmarshal(b *testing.B) {
+	data, err := waBinary.Marshal(benchNode)
+	if err != nil {
+		b.Fatal(err)
+	}
+	var buf bytes.Buffer
+	buf.WriteByte(2)
+	zw := zlib.NewWriter(&buf)
+	_, _ = zw.Write(data[1:])
+	_ = zw.Close()
+	compressed := buf.Bytes()
+	b.ReportAllocs()
+	for b.Loop() {
+		_, err = waBinary.UnpackAndUnmarshal(compressed)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func compressFrame(data []byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte(2)
+	zw := zlib.NewWriter(&buf)
+	_, _ = zw.Write(data)
+	_ = zw.Close()
+	return buf.Bytes()
+}
+
+func TestUnpackAndUnmarshal(t *testing.T) {
+	data, err := waBinary.Marshal(benchNode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, err := waBinary.Unmarshal(data[1:])
+	if err != nil {
+		t.Fatalf("Failed to decode full frame: %v", err)
+	}
+	streamed, err := waBinary.UnpackAndUnmarshal(compressFrame(data[1:]))
+	if err != nil {
+		t.Fatalf("Failed to stream decode full frame: %v", err)
+	}
+	if expected.XMLString() != streamed.XMLString() {
+		t.Errorf("Stream decoded node doesn't match:\n%s\n%s", expected.XMLString(), streamed.XMLString())
+	}
+}
+
+func TestUnpackAndUnmarshalTruncated(t *testing.T) {
+	data, err := waBinary.Marshal(benchNode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = data[1:]
+	for i := 0; i < len(data); i++ {
+		_, sliceErr := waBinary.Unmarshal(data[:i])
+		streamed, streamErr := waBinary.UnpackAndUnmarshal(compressFrame(data[:i]))
+		if sliceErr == nil {
+			t.Fatalf("Decoding frame truncated to %d bytes didn't fail", i)
+		} else if streamErr == nil {
+			t.Fatalf("Stream decoding frame truncated to %d bytes didn't fail, got %s", i, streamed.XMLString())
+		} else if errors.Is(sliceErr, io.EOF) != errors.Is(streamErr, io.EOF) {
+			t.Fatalf("Decoding frame truncated to %d bytes returned different errors: %v / %v", i, sliceErr, streamErr)
+		}
+	}
+}
@@ -9,8 +9,10 @@ package binary
 import (
 	"bytes"
 	"compress/zlib"
+	"errors"
 	"fmt"
 	"io"
+	"sync"
 )
 
 // Unpack unpacks the given decrypted data from the WhatsApp web API.
@@ -21,11 +23,59 @@ import (
 func Unpack(data []byte) ([]byte, error) {
 	dataType, data := data[0], data[1:]
 	if 2&dataType > 0 {
-		if decompressor, err := zlib.NewReader(bytes.NewReader(data)); err != nil {
+		decompressor, err := getZlibReader(data)
+		if err != nil {
 			return nil, fmt.Errorf("failed to create zlib reader: %w", err)
-		} else if data, err = io.ReadAll(decompressor); err != nil {
+		}
+		data, err = io.ReadAll(decompressor)
+		zlibReaderPool.Put(decompressor)
+		if err != nil {
 			return nil, err
 		}
 	}
 	return data, nil
 }
+
+// zlib readers allocate a large amount of internal state, so they're reused.
+// The output buffers can't be pooled, because decoded nodes reference the unpacked data directly.
+var zlibReaderPool sync.Pool
+
+func getZlibReader(data []byte) (io.ReadCloser, error) {
+	if reader, ok := zlibReaderPool.Get().(io.ReadCloser); ok {
+		err := reader.(zlib.Resetter).Reset(bytes.NewReader(data), nil)
+		return reader, err
+	}
+	return zlib.NewReader(bytes.NewReader(data))
+}
+
+// IsCompressed returns true if the given data from the WhatsApp web API is zlib-compressed.
+func IsCompressed(data []byte) bool {
+	return len(data) > 0 && 2&data[0] > 0
+}
+
+// UnpackAndUnmarshal does the same as Unpack followed by Unmarshal, but compressed data is decompressed
+// in a streaming fashion directly into the decoder, so the whole decompressed frame is never held in memory at once.
+//
+// Streaming decoding copies all byte values instead of referencing the input, so it's slower for small frames.
+// It's only meant for large compressed frames like history sync notifications.
+func UnpackAndUnmarshal(data []byte) (*Node, error) {
+	if !IsCompressed(data) {
+		return Unmarshal(data[1:])
+	}
+	decompressor, err := getZlibReader(data[1:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to create zlib reader: %w", err)
+	}
+	defer zlibReaderPool.Put(decompressor)
+	r := newStreamDecoder(decompressor)
+	n, err := r.readNode()
+	if err != nil {
+		return nil, err
+	} else if _, err = r.src.ReadByte(); err == nil {
+		leftover, _ := io.Copy(io.Discard, r.src)
+		return n, fmt.Errorf("%d leftover bytes after decoding", leftover+1)
+	} else if !errors.Is(err, io.EOF) {
+		return n, err
+	}
+	return n, nil
+}
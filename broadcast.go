@@ -15,11 +15,11 @@ import (
 	"go.mau.fi/whatsmeow/types"
 )
 
-func (cli *Client) getBroadcastListParticipants(ctx context.Context, jid types.JID) ([]types.JID, error) {
+func (cli *Client) getBroadcastListParticipants(ctx context.Context, jid types.JID, statusPrivacy *types.StatusPrivacy) ([]types.JID, error) {
 	var list []types.JID
 	var err error
 	if jid == types.StatusBroadcastJID {
-		list, err = cli.getStatusBroadcastRecipients(ctx)
+		list, err = cli.getStatusBroadcastRecipients(ctx, statusPrivacy)
 	} else {
 		return nil, ErrBroadcastListUnsupported
 	}
@@ -44,12 +44,14 @@ func (cli *Client) getBroadcastListParticipants(ctx context.Context, jid types.J
 	return list, nil
 }
 
-func (cli *Client) getStatusBroadcastRecipients(ctx context.Context) ([]types.JID, error) {
-	statusPrivacyOptions, err := cli.GetStatusPrivacy()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get status privacy: %w", err)
+func (cli *Client) getStatusBroadcastRecipients(ctx context.Context, statusPrivacy *types.StatusPrivacy) ([]types.JID, error) {
+	if statusPrivacy == nil {
+		statusPrivacyOptions, err := cli.GetStatusPrivacy()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get status privacy: %w", err)
+		}
+		statusPrivacy = &statusPrivacyOptions[0]
 	}
-	statusPrivacy := statusPrivacyOptions[0]
 	if statusPrivacy.Type == types.StatusPrivacyTypeWhitelist {
 		// Whitelist mode, just return the list
 		return statusPrivacy.List, nil
@@ -141,3 +143,31 @@ func (cli *Client) GetStatusPrivacy() ([]types.StatusPrivacy, error) {
 	}
 	return outputs, nil
 }
+
+// SetStatusPrivacy changes the default list of users who will receive status messages.
+//
+// The list is only used for the blacklist and whitelist types. For StatusPrivacyTypeContacts, it should be empty.
+func (cli *Client) SetStatusPrivacy(ctx context.Context, privacyType types.StatusPrivacyType, list []types.JID) error {
+	users := make([]waBinary.Node, len(list))
+	for i, jid := range list {
+		users[i] = waBinary.Node{
+			Tag:   "user",
+			Attrs: waBinary.Attrs{"jid": jid.ToNonAD()},
+		}
+	}
+	_, err := cli.sendIQ(infoQuery{
+		Context:   ctx,
+		Namespace: "status",
+		Type:      iqSet,
+		To:        types.ServerJID,
+		Content: []waBinary.Node{{
+			Tag: "privacy",
+			Content: []waBinary.Node{{
+				Tag:     "list",
+				Attrs:   waBinary.Attrs{"type": string(privacyType)},
+				Content: users,
+			}},
+		}},
+	})
+	return err
+}
@@ -12,9 +12,11 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"hash/fnv"
 	"net/http"
 	"net/url"
 	"runtime/debug"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -27,7 +29,9 @@ import (
 
 	"go.mau.fi/whatsmeow/appstate"
 	waBinary "go.mau.fi/whatsmeow/binary"
+	"go.mau.fi/whatsmeow/proto/waCommon"
 	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/proto/waHistorySync"
 	"go.mau.fi/whatsmeow/proto/waWa6"
 	"go.mau.fi/whatsmeow/proto/waWeb"
 	"go.mau.fi/whatsmeow/socket"
@@ -46,13 +50,15 @@ type nodeHandler func(node *waBinary.Node)
 var nextHandlerID uint32
 
 type wrappedEventHandler struct {
-	fn EventHandlerWithSuccessStatus
-	id uint32
+	fn       EventHandlerWithSuccessStatus
+	id       uint32
+	priority int
 }
 
 type deviceCache struct {
-	devices []types.JID
-	dhash   string
+	devices   []types.JID
+	dhash     string
+	fetchedAt time.Time
 }
 
 // Client contains everything necessary to connect to and interact with the WhatsApp web API.
@@ -73,15 +79,42 @@ type Client struct {
 	InitialAutoReconnect  bool
 	LastSuccessfulConnect time.Time
 	AutoReconnectErrors   int
+	serverReconnectDelay  atomic.Int64
 	// AutoReconnectHook is called when auto-reconnection fails. If the function returns false,
 	// the client will not attempt to reconnect. The number of retries can be read from AutoReconnectErrors.
 	AutoReconnectHook func(error) bool
 	// If SynchronousAck is set, acks for messages will only be sent after all event handlers return.
-	SynchronousAck             bool
+	SynchronousAck bool
+	// If ManualDeliveryReceipts is set, delivery receipts for incoming messages won't be sent automatically.
+	// Applications must call SendMessageReceipt themselves after processing each message.
+	ManualDeliveryReceipts bool
+	// If DisableReadReceipts is set, MarkRead won't send read receipts to the sender regardless of the account's
+	// privacy settings, like when read receipts are disabled on the phone. Delivery receipts are still sent.
+	DisableReadReceipts bool
+	// If DispatchRevokeEvents is set, incoming revokes are dispatched as events.MessageRevoke
+	// instead of as events.Message with a protocol message.
+	DispatchRevokeEvents bool
+	// If IsolateHandlerPanics is set, each event handler is called with its own recover,
+	// so a panicking handler doesn't prevent the remaining handlers from receiving the event.
+	IsolateHandlerPanics bool
+	// OnHandlerPanic is called (if set) after a panic in an event handler is recovered.
+	OnHandlerPanic func(evt any, panicErr any, stack []byte)
+	// EventJournal can be set to record dispatched events, so that unhandled ones can be replayed with ReplayEvents.
+	EventJournal               EventJournal
 	EnableDecryptedEventBuffer bool
 	lastDecryptedBufferClear   time.Time
 
 	DisableLoginAutoReconnect bool
+	// DefaultIQTimeout is the timeout for info queries that don't specify one. Defaults to 75 seconds if zero.
+	// Methods that take a context can also be cancelled using the context.
+	DefaultIQTimeout time.Duration
+	// If ResendIQsOnReconnect is set, idempotent info queries (e.g. usync, group info and profile pictures)
+	// that are sent while disconnected or interrupted by a disconnection will wait for the client to reconnect
+	// and be sent again, instead of failing with ErrNotConnected. The query timeout still applies.
+	ResendIQsOnReconnect bool
+	// If KeepDataOnLogout is set, the device store won't be deleted when the device is logged out
+	// from the phone or by the server. Use RelinkDevice to pair again while keeping the local data.
+	KeepDataOnLogout bool
 
 	sendActiveReceipts atomic.Uint32
 
@@ -98,15 +131,21 @@ type Client struct {
 
 	historySyncNotifications  chan *waE2E.HistorySyncNotification
 	historySyncHandlerStarted atomic.Bool
+	// Chunk ordering state for history sync notifications, only accessed by handleHistorySyncNotificationLoop
+	historySyncNextChunk      map[waE2E.HistorySyncNotification_HistorySyncType]uint32
+	historySyncHeldChunks     map[waE2E.HistorySyncNotification_HistorySyncType]map[uint32]*waE2E.HistorySyncNotification
 	ManualHistorySyncDownload bool
 
 	uploadPreKeysLock sync.Mutex
 	lastPreKeyUpload  time.Time
 
+	outboxLock sync.Mutex
+
 	mediaConnCache *MediaConn
 	mediaConnLock  sync.Mutex
 
 	responseWaiters     map[string]chan<- *waBinary.Node
+	responseCleanups    map[string]func()
 	responseWaitersLock sync.Mutex
 
 	nodeHandlers      map[string]nodeHandler
@@ -114,6 +153,17 @@ type Client struct {
 	eventHandlers     []wrappedEventHandler
 	eventHandlersLock sync.RWMutex
 
+	// If ParallelMessageWorkers is set, incoming messages are decrypted and handled by that many workers in parallel.
+	// Messages in the same chat are always handled by the same worker, so the order within each chat is preserved,
+	// but messages may be handled out of order relative to other chats and to other node types (e.g. receipts).
+	// Messages that are still queued when the connection drops are handled after reconnecting.
+	// This must be set before connecting.
+	ParallelMessageWorkers int
+	// The worker queues live as long as the client, so that queued messages aren't lost when reconnecting.
+	messageWorkerQueues  []chan *waBinary.Node
+	handlerQueueLoopLock sync.Mutex
+	sessionLocks         [sessionLockStripes]sync.Mutex
+
 	messageRetries     map[string]int
 	messageRetriesLock sync.Mutex
 
@@ -123,25 +173,49 @@ type Client struct {
 	appStateKeyRequests     map[string]time.Time
 	appStateKeyRequestsLock sync.RWMutex
 
-	messageSendLock sync.Mutex
+	messageSendLock  sync.Mutex
+	idempotencyLocks [idempotencyLockStripes]sync.Mutex
+
+	stats trafficStats
 
 	privacySettingsCache atomic.Value
+	serverPropsCache     atomic.Value
+	abPropsCache         atomic.Value
 
 	groupCache           map[types.JID]*groupMetaCache
 	groupCacheLock       sync.Mutex
 	userDevicesCache     map[types.JID]deviceCache
 	userDevicesCacheLock sync.Mutex
+	userAboutCache       map[types.JID]types.UserAbout
+	userAboutCacheList   [userAboutCacheSize]types.JID
+	userAboutCachePtr    int
+	userAboutCacheLock   sync.Mutex
 
 	recentMessagesMap  map[recentMessageKey]RecentMessage
 	recentMessagesList [recentMessagesSize]recentMessageKey
 	recentMessagesPtr  int
 	recentMessagesLock sync.RWMutex
 
+	// If MessageDedupCacheSize is set, the IDs of that many recently received messages are remembered,
+	// and duplicate Message events (e.g. caused by retries or offline resends) are not dispatched.
+	MessageDedupCacheSize int
+	dedupMessagesMap      map[dedupMessageKey]struct{}
+	dedupMessagesList     []dedupMessageKey
+	dedupMessagesPtr      int
+	dedupMessagesLock     sync.Mutex
+
+	mediaRetryWaiters     map[types.MessageID]chan *events.MediaRetry
+	mediaRetryWaitersLock sync.Mutex
+
 	sessionRecreateHistory     map[types.JID]time.Time
 	sessionRecreateHistoryLock sync.Mutex
 	// GetMessageForRetry is used to find the source message for handling retry receipts
 	// when the message is not found in the recently sent message cache.
 	GetMessageForRetry func(requester, to types.JID, id types.MessageID) *waE2E.Message
+	// GetMessageForPlaceholderResend is used to find messages when the primary device asks this client to resend
+	// messages it couldn't decrypt (the "waiting for this message" placeholder). If it's nil or returns nil,
+	// the message is not included in the response.
+	GetMessageForPlaceholderResend func(ctx context.Context, key *waCommon.MessageKey) *waWeb.WebMessageInfo
 	// PreRetryCallback is called before a retry receipt is accepted.
 	// If it returns false, the accepting will be cancelled and the retry receipt will be ignored.
 	PreRetryCallback func(receipt *events.Receipt, id types.MessageID, retryCount int, msg *waE2E.Message) bool
@@ -150,6 +224,11 @@ type Client struct {
 	// the client will disconnect.
 	PrePairCallback func(jid types.JID, platform, businessName string) bool
 
+	// GenerateMessageIDHook can be set to override the message IDs generated by GenerateMessageID,
+	// e.g. to use an application-specific ID scheme. IDs must be unique and should look like normal
+	// WhatsApp message IDs (uppercase hex), otherwise other clients may not handle them correctly.
+	GenerateMessageIDHook func() types.MessageID
+
 	// GetClientPayload is called to get the client payload for connecting to the server.
 	// This should NOT be used for WhatsApp (to change the OS name, update fields in store.BaseClientPayload directly).
 	GetClientPayload func() *waWa6.ClientPayload
@@ -159,6 +238,11 @@ type Client struct {
 	// If false, decrypting a message from untrusted devices will fail.
 	AutoTrustIdentity bool
 
+	// If true, the client will automatically send an available presence after connecting (or after the push name
+	// is received from app state if it wasn't known yet) and an unavailable presence before Disconnect.
+	// Without an available presence, other users will see "-" as the name of this account.
+	AutoSendPresence bool
+
 	// Should SubscribePresence return an error if no privacy token is stored for the user?
 	ErrorOnSubscribePresenceWithoutToken bool
 
@@ -176,6 +260,12 @@ type Client struct {
 	proxyOnlyLogin bool
 	http           *http.Client
 
+	// If set to a value above 1, media downloads are split into this many HTTP range requests
+	// which are done in parallel. This can significantly speed up large downloads from far-away servers.
+	// Small files that fit in the first range request and files whose size isn't specified in the message
+	// are still downloaded with a single request.
+	ParallelMediaDownloads int
+
 	// This field changes the client to act like a Messenger client instead of a WhatsApp one.
 	//
 	// Note that you cannot use a Messenger account just by setting this field, you must use a
@@ -233,6 +323,7 @@ func NewClient(deviceStore *store.Device, log waLog.Logger) *Client {
 		sendLog:            log.Sub("Send"),
 		uniqueID:           fmt.Sprintf("%d.%d-", uniqueIDPrefix[0], uniqueIDPrefix[1]),
 		responseWaiters:    make(map[string]chan<- *waBinary.Node),
+		responseCleanups:   make(map[string]func()),
 		eventHandlers:      make([]wrappedEventHandler, 0, 1),
 		messageRetries:     make(map[string]int),
 		handlerQueue:       make(chan *waBinary.Node, handlerQueueSize),
@@ -243,9 +334,13 @@ func NewClient(deviceStore *store.Device, log waLog.Logger) *Client {
 		incomingRetryRequestCounter: make(map[incomingRetryKey]int),
 
 		historySyncNotifications: make(chan *waE2E.HistorySyncNotification, 32),
+		mediaRetryWaiters:        make(map[types.MessageID]chan *events.MediaRetry),
+		historySyncNextChunk:     make(map[waE2E.HistorySyncNotification_HistorySyncType]uint32),
+		historySyncHeldChunks:    make(map[waE2E.HistorySyncNotification_HistorySyncType]map[uint32]*waE2E.HistorySyncNotification),
 
 		groupCache:       make(map[types.JID]*groupMetaCache),
 		userDevicesCache: make(map[types.JID]deviceCache),
+		userAboutCache:   make(map[types.JID]types.UserAbout),
 
 		recentMessagesMap:      make(map[recentMessageKey]RecentMessage, recentMessagesSize),
 		sessionRecreateHistory: make(map[types.JID]time.Time),
@@ -274,6 +369,7 @@ func NewClient(deviceStore *store.Device, log waLog.Logger) *Client {
 		"ib":           cli.handleIB,
 		// Apparently there's also an <error> node which can have a code=479 and means "Invalid stanza sent (smax-invalid)"
 	}
+	cli.stats.reset()
 	return cli
 }
 
@@ -335,7 +431,11 @@ func (cli *Client) SetProxy(proxy Proxy, opts ...SetProxyOptions) {
 		cli.socksProxy = nil
 	}
 	if !opt.NoMedia {
-		transport := cli.http.Transport.(*http.Transport)
+		transport, ok := cli.http.Transport.(*http.Transport)
+		if !ok {
+			cli.Log.Warnf("Not setting proxy for media: custom HTTP client doesn't use *http.Transport")
+			return
+		}
 		transport.Proxy = proxy
 		transport.Dial = nil
 		transport.DialContext = nil
@@ -362,7 +462,11 @@ func (cli *Client) SetSOCKSProxy(px proxy.Dialer, opts ...SetProxyOptions) {
 		cli.proxy = nil
 	}
 	if !opt.NoMedia {
-		transport := cli.http.Transport.(*http.Transport)
+		transport, ok := cli.http.Transport.(*http.Transport)
+		if !ok {
+			cli.Log.Warnf("Not setting proxy for media: custom HTTP client doesn't use *http.Transport")
+			return
+		}
 		transport.Proxy = nil
 		transport.Dial = cli.socksProxy.Dial
 		contextDialer, ok := cli.socksProxy.(proxy.ContextDialer)
@@ -374,6 +478,26 @@ func (cli *Client) SetSOCKSProxy(px proxy.Dialer, opts ...SetProxyOptions) {
 	}
 }
 
+// SetMediaHTTPClient sets the HTTP client used for media uploads and downloads.
+// This can be used to apply custom timeouts, transports (e.g. for metrics or tracing) or proxies.
+//
+// The client replaces the default one entirely, so SetProxy and SetSOCKSProxy must be called after this
+// if they're used, and they only affect the client if its Transport is an *http.Transport.
+// The client can also be passed to GetLatestVersion to use it for version fetches.
+func (cli *Client) SetMediaHTTPClient(client *http.Client) {
+	if client == nil {
+		client = &http.Client{
+			Transport: (http.DefaultTransport.(*http.Transport)).Clone(),
+		}
+	}
+	cli.http = client
+}
+
+// GetMediaHTTPClient returns the HTTP client used for media uploads and downloads.
+func (cli *Client) GetMediaHTTPClient() *http.Client {
+	return cli.http
+}
+
 // ToggleProxyOnlyForLogin changes whether the proxy set with SetProxy or related methods
 // is only used for the pre-login websocket and not authenticated websockets.
 func (cli *Client) ToggleProxyOnlyForLogin(only bool) {
@@ -437,6 +561,13 @@ func (cli *Client) SetWSDialer(dialer *websocket.Dialer) {
 // Connect connects the client to the WhatsApp web websocket. After connection, it will either
 // authenticate if there's data in the device store, or emit a QREvent to set up a new link.
 func (cli *Client) Connect() error {
+	return cli.ConnectContext(context.Background())
+}
+
+// ConnectContext is like Connect, but the given context is used for the connection handshake,
+// e.g. when computing the shared secret with store.Device.ExternalNoiseKey.
+// Cancelling the context after ConnectContext returns doesn't affect the connection.
+func (cli *Client) ConnectContext(ctx context.Context) error {
 	if cli == nil {
 		return ErrClientIsNil
 	}
@@ -444,7 +575,7 @@ func (cli *Client) Connect() error {
 	cli.socketLock.Lock()
 	defer cli.socketLock.Unlock()
 
-	err := cli.unlockedConnect()
+	err := cli.unlockedConnect(ctx)
 	if exhttp.IsNetworkError(err) && cli.InitialAutoReconnect && cli.EnableAutoReconnect {
 		cli.Log.Errorf("Initial connection failed but reconnecting in background (%v)", err)
 		go cli.dispatchEvent(&events.Disconnected{})
@@ -454,14 +585,14 @@ func (cli *Client) Connect() error {
 	return err
 }
 
-func (cli *Client) connect() error {
+func (cli *Client) connect(ctx context.Context) error {
 	cli.socketLock.Lock()
 	defer cli.socketLock.Unlock()
 
-	return cli.unlockedConnect()
+	return cli.unlockedConnect(ctx)
 }
 
-func (cli *Client) unlockedConnect() error {
+func (cli *Client) unlockedConnect(ctx context.Context) error {
 	if cli.socket != nil {
 		if !cli.socket.IsConnected() {
 			cli.unlockedDisconnect()
@@ -499,7 +630,7 @@ func (cli *Client) unlockedConnect() error {
 	if err := fs.Connect(); err != nil {
 		fs.Close(0)
 		return err
-	} else if err = cli.doHandshake(fs, *keys.NewKeyPair()); err != nil {
+	} else if err = cli.doHandshake(ctx, fs, *keys.NewKeyPair()); err != nil {
 		fs.Close(0)
 		return fmt.Errorf("noise handshake failed: %w", err)
 	}
@@ -552,12 +683,15 @@ func (cli *Client) autoReconnect() {
 	}
 	for {
 		autoReconnectDelay := time.Duration(cli.AutoReconnectErrors) * 2 * time.Second
+		if serverDelay := time.Duration(cli.serverReconnectDelay.Swap(0)); serverDelay > autoReconnectDelay {
+			autoReconnectDelay = serverDelay
+		}
 		cli.Log.Debugf("Automatically reconnecting after %v", autoReconnectDelay)
 		cli.AutoReconnectErrors++
 		if cli.expectedDisconnect.WaitTimeout(autoReconnectDelay) {
 			return
 		}
-		err := cli.connect()
+		err := cli.connect(cli.BackgroundEventCtx)
 		if errors.Is(err, ErrAlreadyConnected) {
 			cli.Log.Debugf("Connect() said we're already connected after autoreconnect sleep")
 			return
@@ -596,6 +730,12 @@ func (cli *Client) Disconnect() {
 	if cli == nil {
 		return
 	}
+	if cli.AutoSendPresence && cli.IsLoggedIn() && len(cli.Store.PushName) > 0 {
+		err := cli.SendPresence(types.PresenceUnavailable)
+		if err != nil {
+			cli.Log.Warnf("Failed to send unavailable presence before disconnecting: %v", err)
+		}
+	}
 	cli.socketLock.Lock()
 	cli.expectDisconnect()
 	cli.unlockedDisconnect()
@@ -629,27 +769,50 @@ func (cli *Client) Logout(ctx context.Context) error {
 	if ownID.IsEmpty() {
 		return ErrNotLoggedIn
 	}
+	err := cli.removeCompanionDevice(ctx, ownID)
+	if err != nil {
+		return fmt.Errorf("error sending logout request: %w", err)
+	}
+	cli.Disconnect()
+	err = cli.Store.Delete(ctx)
+	if err != nil {
+		return fmt.Errorf("error deleting data from store: %w", err)
+	}
+	return nil
+}
+
+// RemoveLinkedDevice asks the server to unlink the given companion device from the account.
+//
+// Use GetLinkedDevices to find the JIDs of linked devices. To unlink the current device, use Logout instead,
+// which also deletes the local data. Note that the server may only allow the primary device to unlink other devices.
+func (cli *Client) RemoveLinkedDevice(ctx context.Context, device types.JID) error {
+	if cli == nil {
+		return ErrClientIsNil
+	}
+	ownID := cli.getOwnID()
+	if ownID.IsEmpty() {
+		return ErrNotLoggedIn
+	} else if device.User != ownID.User || device.Device == 0 {
+		return fmt.Errorf("%s is not a companion device of the current account", device)
+	}
+	return cli.removeCompanionDevice(ctx, device)
+}
+
+func (cli *Client) removeCompanionDevice(ctx context.Context, device types.JID) error {
 	_, err := cli.sendIQ(infoQuery{
+		Context:   ctx,
 		Namespace: "md",
 		Type:      "set",
 		To:        types.ServerJID,
 		Content: []waBinary.Node{{
 			Tag: "remove-companion-device",
 			Attrs: waBinary.Attrs{
-				"jid":    ownID,
+				"jid":    device,
 				"reason": "user_initiated",
 			},
 		}},
 	})
-	if err != nil {
-		return fmt.Errorf("error sending logout request: %w", err)
-	}
-	cli.Disconnect()
-	err = cli.Store.Delete(ctx)
-	if err != nil {
-		return fmt.Errorf("error deleting data from store: %w", err)
-	}
-	return nil
+	return err
 }
 
 // AddEventHandler registers a new function to receive all events emitted by this client.
@@ -691,9 +854,28 @@ func (cli *Client) AddEventHandler(handler EventHandler) uint32 {
 }
 
 func (cli *Client) AddEventHandlerWithSuccessStatus(handler EventHandlerWithSuccessStatus) uint32 {
+	return cli.AddEventHandlerWithPriority(handler, 0)
+}
+
+// AddEventHandlerWithPriority registers a new event handler with the given priority.
+//
+// Handlers with a higher priority are called before handlers with a lower priority, and handlers with
+// the same priority are called in registration order. Handlers registered with AddEventHandler and
+// AddEventHandlerWithSuccessStatus have priority 0. For example, a handler that persists events could
+// use a positive priority to ensure it always runs before any business logic handlers.
+//
+// If a handler returns false, the remaining handlers are not called (see AddEventHandlerWithSuccessStatus).
+func (cli *Client) AddEventHandlerWithPriority(handler EventHandlerWithSuccessStatus, priority int) uint32 {
 	nextID := atomic.AddUint32(&nextHandlerID, 1)
 	cli.eventHandlersLock.Lock()
-	cli.eventHandlers = append(cli.eventHandlers, wrappedEventHandler{handler, nextID})
+	index := slices.IndexFunc(cli.eventHandlers, func(existing wrappedEventHandler) bool {
+		return existing.priority < priority
+	})
+	if index == -1 {
+		index = len(cli.eventHandlers)
+	}
+	// The list is copied on write, so that dispatchEvent can iterate over it without holding the lock
+	cli.eventHandlers = slices.Insert(slices.Clip(cli.eventHandlers), index, wrappedEventHandler{handler, nextID, priority})
 	cli.eventHandlersLock.Unlock()
 	return nextID
 }
@@ -701,33 +883,78 @@ func (cli *Client) AddEventHandlerWithSuccessStatus(handler EventHandlerWithSucc
 // RemoveEventHandler removes a previously registered event handler function.
 // If the function with the given ID is found, this returns true.
 //
-// N.B. Do not run this directly from an event handler. That would cause a deadlock because the
-// event dispatcher holds a read lock on the event handler list, and this method wants a write lock
-// on the same list. Instead run it in a goroutine:
-//
-//	func (mycli *MyClient) myEventHandler(evt interface{}) {
-//		if noLongerWantEvents {
-//			go mycli.WAClient.RemoveEventHandler(mycli.eventHandlerID)
-//		}
-//	}
+// This is safe to call from inside an event handler. If the handler is removed while an event
+// is being dispatched, it may still receive that event, but it won't receive any further events.
 func (cli *Client) RemoveEventHandler(id uint32) bool {
 	cli.eventHandlersLock.Lock()
 	defer cli.eventHandlersLock.Unlock()
-	for index := range cli.eventHandlers {
-		if cli.eventHandlers[index].id == id {
-			if index == 0 {
-				cli.eventHandlers[0].fn = nil
-				cli.eventHandlers = cli.eventHandlers[1:]
-				return true
-			} else if index < len(cli.eventHandlers)-1 {
-				copy(cli.eventHandlers[index:], cli.eventHandlers[index+1:])
+	index := slices.IndexFunc(cli.eventHandlers, func(handler wrappedEventHandler) bool {
+		return handler.id == id
+	})
+	if index == -1 {
+		return false
+	}
+	cli.eventHandlers = slices.Delete(slices.Clone(cli.eventHandlers), index, index+1)
+	return true
+}
+
+// AddEventHandlerOnce registers an event handler that is automatically removed after it returns true.
+//
+// The handler is called for every event until it returns true, so it can ignore events it's not interested in:
+//
+//	cli.AddEventHandlerOnce(func(evt any) bool {
+//		_, ok := evt.(*events.Connected)
+//		if ok {
+//			fmt.Println("Connected!")
+//		}
+//		return ok
+//	})
+//
+// Calls to the handler are serialized, so it's never called again after it has returned true,
+// even if events are dispatched concurrently. The handler must therefore not dispatch events itself.
+func (cli *Client) AddEventHandlerOnce(handler func(evt any) (done bool)) uint32 {
+	var lock sync.Mutex
+	var done bool
+	var id uint32
+	var idSet sync.WaitGroup
+	idSet.Add(1)
+	id = cli.AddEventHandler(func(evt any) {
+		lock.Lock()
+		defer lock.Unlock()
+		if done || !handler(evt) {
+			return
+		}
+		done = true
+		idSet.Wait()
+		cli.RemoveEventHandler(id)
+	})
+	idSet.Done()
+	return id
+}
+
+// WaitForEvent waits until the client dispatches an event of the given type and returns it.
+//
+//	connected, err := whatsmeow.WaitForEvent[*events.Connected](ctx, cli)
+func WaitForEvent[T any](ctx context.Context, cli *Client) (T, error) {
+	ch := make(chan T, 1)
+	id := cli.AddEventHandlerOnce(func(evt any) bool {
+		typedEvt, ok := evt.(T)
+		if ok {
+			select {
+			case ch <- typedEvt:
+			default:
 			}
-			cli.eventHandlers[len(cli.eventHandlers)-1].fn = nil
-			cli.eventHandlers = cli.eventHandlers[:len(cli.eventHandlers)-1]
-			return true
 		}
+		return ok
+	})
+	select {
+	case evt := <-ch:
+		return evt, nil
+	case <-ctx.Done():
+		cli.RemoveEventHandler(id)
+		var zero T
+		return zero, ctx.Err()
 	}
-	return false
 }
 
 // RemoveEventHandlers removes all event handlers that have been registered with AddEventHandler
@@ -737,7 +964,21 @@ func (cli *Client) RemoveEventHandlers() {
 	cli.eventHandlersLock.Unlock()
 }
 
+// largeFrameSize is the compressed size above which frames are decompressed and decoded in a streaming fashion.
+const largeFrameSize = 256 * 1024
+
 func (cli *Client) handleFrame(data []byte) {
+	cli.stats.recordReceivedFrame(len(data))
+	if waBinary.IsCompressed(data) && len(data) > largeFrameSize {
+		node, err := waBinary.UnpackAndUnmarshal(data)
+		if err != nil {
+			cli.Log.Warnf("Failed to decode large compressed frame: %v", err)
+			cli.Log.Debugf("Errored frame hex: %s", hex.EncodeToString(data))
+			return
+		}
+		cli.handleNode(node)
+		return
+	}
 	decompressed, err := waBinary.Unpack(data)
 	if err != nil {
 		cli.Log.Warnf("Failed to decompress frame: %v", err)
@@ -750,6 +991,11 @@ func (cli *Client) handleFrame(data []byte) {
 		cli.Log.Debugf("Errored frame hex: %s", hex.EncodeToString(decompressed))
 		return
 	}
+	cli.handleNode(node)
+}
+
+func (cli *Client) handleNode(node *waBinary.Node) {
+	cli.stats.recordReceivedNode(node.Tag)
 	cli.recvLog.Debugf("%s", node.XMLString())
 	if node.Tag == "xmlstreamend" {
 		if !cli.isExpectedDisconnect() {
@@ -772,14 +1018,111 @@ func (cli *Client) handleFrame(data []byte) {
 	}
 }
 
+const messageWorkerQueueSize = 256
+
+// startMessageWorkers starts a goroutine for each message worker queue. The workers run until stop is closed,
+// after which wg is marked as done once they've finished handling the node they were processing.
+func (cli *Client) startMessageWorkers(stop <-chan struct{}, wg *sync.WaitGroup) {
+	if len(cli.messageWorkerQueues) != cli.ParallelMessageWorkers {
+		// Any messages still in the old queues are dropped if the worker count was changed
+		cli.messageWorkerQueues = make([]chan *waBinary.Node, cli.ParallelMessageWorkers)
+		for i := range cli.messageWorkerQueues {
+			cli.messageWorkerQueues[i] = make(chan *waBinary.Node, messageWorkerQueueSize)
+		}
+	}
+	cli.Log.Debugf("Starting %d parallel message workers", len(cli.messageWorkerQueues))
+	wg.Add(len(cli.messageWorkerQueues))
+	for _, queue := range cli.messageWorkerQueues {
+		go func() {
+			defer wg.Done()
+			cli.messageWorkerLoop(stop, queue)
+		}()
+	}
+}
+
+func (cli *Client) messageWorkerLoop(stop <-chan struct{}, queue <-chan *waBinary.Node) {
+	for {
+		select {
+		case node := <-queue:
+			start := time.Now()
+			cli.nodeHandlers[node.Tag](node)
+			if duration := time.Since(start); duration > 5*time.Second {
+				cli.Log.Warnf("Node handling took %s for %s", duration, node.XMLString())
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// getMessageWorker returns the worker queue for the chat the given message node belongs to.
+//
+// Chats addressed by LID are mapped to phone numbers first when the mapping is known,
+// so that messages in the same chat go to the same worker regardless of the addressing mode.
+func (cli *Client) getMessageWorker(ctx context.Context, node *waBinary.Node) chan<- *waBinary.Node {
+	ag := node.AttrGetter()
+	chat := ag.OptionalJIDOrEmpty("from")
+	pnAttr := "sender_pn"
+	if recipient := ag.OptionalJIDOrEmpty("recipient"); !recipient.IsEmpty() && chat.Server != types.GroupServer {
+		// Messages sent by our other devices have the chat in the recipient attribute
+		chat = recipient
+		pnAttr = "peer_recipient_pn"
+	}
+	chat = chat.ToNonAD()
+	if chat.Server == types.HiddenUserServer {
+		if pn := ag.OptionalJIDOrEmpty(pnAttr); !pn.IsEmpty() {
+			chat = pn.ToNonAD()
+		} else if pn, err := cli.Store.LIDs.GetPNForLID(ctx, chat); err != nil {
+			cli.Log.Warnf("Failed to get phone number for %s to choose message worker: %v", chat, err)
+		} else if !pn.IsEmpty() {
+			chat = pn.ToNonAD()
+		}
+	}
+	hasher := fnv.New32a()
+	_, _ = hasher.Write([]byte(chat.String()))
+	return cli.messageWorkerQueues[hasher.Sum32()%uint32(len(cli.messageWorkerQueues))]
+}
+
+const sessionLockStripes = 64
+
+// lockSignalSession locks the Signal session with the given user, so that multiple messages
+// from the same device aren't decrypted in parallel.
+func (cli *Client) lockSignalSession(jid types.JID) func() {
+	hasher := fnv.New32a()
+	_, _ = hasher.Write([]byte(jid.SignalAddress().String()))
+	lock := &cli.sessionLocks[hasher.Sum32()%sessionLockStripes]
+	lock.Lock()
+	return lock.Unlock
+}
+
 func (cli *Client) handlerQueueLoop(ctx context.Context) {
+	// Wait for the loop of the previous connection and its message workers to stop,
+	// so that the queues are never consumed by multiple loops at once.
+	cli.handlerQueueLoopLock.Lock()
+	defer cli.handlerQueueLoopLock.Unlock()
 	ticker := time.NewTicker(30 * time.Second)
 	ticker.Stop()
 	cli.Log.Debugf("Starting handler queue loop")
+	useMessageWorkers := cli.ParallelMessageWorkers > 0
+	if useMessageWorkers {
+		stopWorkers := make(chan struct{})
+		var workersDone sync.WaitGroup
+		cli.startMessageWorkers(stopWorkers, &workersDone)
+		defer func() {
+			close(stopWorkers)
+			workersDone.Wait()
+		}()
+	}
 Loop:
 	for {
 		select {
 		case node := <-cli.handlerQueue:
+			if useMessageWorkers && node.Tag == "message" {
+				// The workers keep running until this loop exits, so this can't block forever.
+				// Nodes are never dropped here, as that would break the order of the chat.
+				cli.getMessageWorker(ctx, node) <- node
+				continue
+			}
 			doneChan := make(chan struct{}, 1)
 			start := time.Now()
 			go func() {
@@ -798,6 +1141,11 @@ Loop:
 					continue Loop
 				case <-ticker.C:
 					cli.Log.Warnf("Node handling is taking long for %s (started %s ago)", node.XMLString(), time.Since(start))
+				case <-ctx.Done():
+					// Don't make the loop of the next connection wait for the slow handler
+					cli.Log.Debugf("Closing handler queue loop while %s is still being handled", node.XMLString())
+					ticker.Stop()
+					return
 				}
 			}
 			cli.Log.Warnf("Continuing handling of %s in background as it's taking too long", node.XMLString())
@@ -826,7 +1174,11 @@ func (cli *Client) sendNodeAndGetData(node waBinary.Node) ([]byte, error) {
 	}
 
 	cli.sendLog.Debugf("%s", node.XMLString())
-	return payload, sock.SendFrame(payload)
+	err = sock.SendFrame(payload)
+	if err == nil {
+		cli.stats.recordSent(node.Tag, len(payload))
+	}
+	return payload, err
 }
 
 func (cli *Client) sendNode(node waBinary.Node) error {
@@ -835,15 +1187,39 @@ func (cli *Client) sendNode(node waBinary.Node) error {
 }
 
 func (cli *Client) dispatchEvent(evt any) (handlerFailed bool) {
-	cli.eventHandlersLock.RLock()
-	defer func() {
-		cli.eventHandlersLock.RUnlock()
-		err := recover()
+	journal := cli.EventJournal
+	if journal == nil {
+		return cli.dispatchEventToHandlers(evt)
+	}
+	seq, err := journal.Append(context.TODO(), evt)
+	if err != nil {
+		cli.Log.Errorf("Failed to append %T to event journal: %v", evt, err)
+		return cli.dispatchEventToHandlers(evt)
+	}
+	handlerFailed = cli.dispatchEventToHandlers(evt)
+	if !handlerFailed {
+		err = journal.MarkHandled(context.TODO(), seq)
 		if err != nil {
-			cli.Log.Errorf("Event handler panicked while handling a %T: %v\n%s", evt, err, debug.Stack())
+			cli.Log.Errorf("Failed to mark %T #%d as handled in event journal: %v", evt, seq, err)
+		}
+	}
+	return
+}
+
+func (cli *Client) dispatchEventToHandlers(evt any) (handlerFailed bool) {
+	cli.eventHandlersLock.RLock()
+	handlers := cli.eventHandlers
+	cli.eventHandlersLock.RUnlock()
+	if cli.IsolateHandlerPanics {
+		for _, handler := range handlers {
+			if !cli.callEventHandler(handler, evt) {
+				return true
+			}
 		}
-	}()
-	for _, handler := range cli.eventHandlers {
+		return false
+	}
+	defer cli.recoverHandlerPanic(evt)
+	for _, handler := range handlers {
 		if !handler.fn(evt) {
 			return true
 		}
@@ -851,6 +1227,25 @@ func (cli *Client) dispatchEvent(evt any) (handlerFailed bool) {
 	return false
 }
 
+func (cli *Client) callEventHandler(handler wrappedEventHandler, evt any) (ok bool) {
+	// Panics are logged but don't count as failures, same as when handler panics aren't isolated
+	ok = true
+	defer cli.recoverHandlerPanic(evt)
+	ok = handler.fn(evt)
+	return
+}
+
+func (cli *Client) recoverHandlerPanic(evt any) {
+	err := recover()
+	if err != nil {
+		stack := debug.Stack()
+		cli.Log.Errorf("Event handler panicked while handling a %T: %v\n%s", evt, err, stack)
+		if cli.OnHandlerPanic != nil {
+			cli.OnHandlerPanic(evt, err, stack)
+		}
+	}
+}
+
 // ParseWebMessage parses a WebMessageInfo object into *events.Message to match what real-time messages have.
 //
 // The chat JID can be found in the Conversation data:
@@ -912,6 +1307,29 @@ func (cli *Client) ParseWebMessage(chatJID types.JID, webMsg *waWeb.WebMessageIn
 	return evt, nil
 }
 
+// ParseHistorySyncConversation parses all messages in the given history sync conversation
+// using [Client.ParseWebMessage], so they can be handled the same way as real-time messages.
+//
+// The messages are returned in the same order as in the conversation. Messages that can't be parsed
+// are skipped, and the errors for them are joined into the returned error.
+func (cli *Client) ParseHistorySyncConversation(conv *waHistorySync.Conversation) ([]*events.Message, error) {
+	chatJID, err := types.ParseJID(conv.GetID())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse chat JID: %w", err)
+	}
+	parsed := make([]*events.Message, 0, len(conv.GetMessages()))
+	var errs []error
+	for _, historyMsg := range conv.GetMessages() {
+		evt, err := cli.ParseWebMessage(chatJID, historyMsg.GetMessage())
+		if err != nil {
+			errs = append(errs, err)
+		} else {
+			parsed = append(parsed, evt)
+		}
+	}
+	return parsed, errors.Join(errs...)
+}
+
 func (cli *Client) StoreLIDPNMapping(ctx context.Context, first, second types.JID) {
 	var lid, pn types.JID
 	if first.Server == types.HiddenUserServer && second.Server == types.DefaultUserServer {
@@ -11,7 +11,6 @@ import (
 	"time"
 
 	waBinary "go.mau.fi/whatsmeow/binary"
-	"go.mau.fi/whatsmeow/store"
 	"go.mau.fi/whatsmeow/types"
 	"go.mau.fi/whatsmeow/types/events"
 )
@@ -33,7 +32,7 @@ func (cli *Client) handleStreamError(node *waBinary.Node) {
 		cli.Log.Infof("Got 515 code, reconnecting...")
 		go func() {
 			cli.Disconnect()
-			err := cli.connect()
+			err := cli.connect(cli.BackgroundEventCtx)
 			if err != nil {
 				cli.Log.Errorf("Failed to reconnect after 515 code: %v", err)
 			}
@@ -42,9 +41,11 @@ func (cli *Client) handleStreamError(node *waBinary.Node) {
 		cli.expectDisconnect()
 		cli.Log.Infof("Got device removed stream error, sending LoggedOut event and deleting session")
 		go cli.dispatchEvent(&events.LoggedOut{OnConnect: false, Reason: events.ConnectFailureLoggedOut})
-		err := cli.Store.Delete(ctx)
-		if err != nil {
-			cli.Log.Warnf("Failed to delete store after device_removed error: %v", err)
+		if !cli.KeepDataOnLogout {
+			err := cli.Store.Delete(ctx)
+			if err != nil {
+				cli.Log.Warnf("Failed to delete store after device_removed error: %v", err)
+			}
 		}
 	case conflictType == "replaced":
 		cli.expectDisconnect()
@@ -53,7 +54,9 @@ func (cli *Client) handleStreamError(node *waBinary.Node) {
 	case code == "503":
 		// This seems to happen when the server wants to restart or something.
 		// The disconnection will be emitted as an events.Disconnected and then the auto-reconnect will do its thing.
-		cli.Log.Warnf("Got 503 stream error, assuming automatic reconnect will handle it")
+		retryAfter := cli.setServerReconnectDelay(node)
+		cli.Log.Warnf("Got 503 stream error (retry after: %s), assuming automatic reconnect will handle it", retryAfter)
+		go cli.dispatchEvent(&events.ServiceUnavailable{OnConnect: false, RetryAfter: retryAfter, Raw: node})
 	case cli.RefreshCAT != nil && (code == events.ConnectFailureCATInvalid.NumberString() || code == events.ConnectFailureCATExpired.NumberString()):
 		cli.Log.Infof("Got %s stream error, refreshing CAT before reconnecting...", code)
 		cli.socketLock.RLock()
@@ -70,6 +73,13 @@ func (cli *Client) handleStreamError(node *waBinary.Node) {
 	}
 }
 
+// setServerReconnectDelay stores the retry hint from a 503 error node, so that the next auto-reconnect waits at least that long.
+func (cli *Client) setServerReconnectDelay(node *waBinary.Node) time.Duration {
+	retryAfter := time.Duration(node.AttrGetter().OptionalInt("retry")) * time.Second
+	cli.serverReconnectDelay.Store(int64(retryAfter))
+	return retryAfter
+}
+
 func (cli *Client) handleIB(node *waBinary.Node) {
 	children := node.GetChildren()
 	for _, child := range children {
@@ -121,9 +131,11 @@ func (cli *Client) handleConnectFailure(node *waBinary.Node) {
 	if reason.IsLoggedOut() {
 		cli.Log.Infof("Got %s connect failure, sending LoggedOut event and deleting session", reason)
 		go cli.dispatchEvent(&events.LoggedOut{OnConnect: true, Reason: reason})
-		err := cli.Store.Delete(ctx)
-		if err != nil {
-			cli.Log.Warnf("Failed to delete store after %d failure: %v", int(reason), err)
+		if !cli.KeepDataOnLogout {
+			err := cli.Store.Delete(ctx)
+			if err != nil {
+				cli.Log.Warnf("Failed to delete store after %d failure: %v", int(reason), err)
+			}
 		}
 	} else if reason == events.ConnectFailureTempBanned {
 		cli.Log.Warnf("Temporary ban connect failure: %s", node.XMLString())
@@ -132,7 +144,7 @@ func (cli *Client) handleConnectFailure(node *waBinary.Node) {
 			Expire: time.Duration(ag.Int("expire")) * time.Second,
 		})
 	} else if reason == events.ConnectFailureClientOutdated {
-		cli.Log.Errorf("Client outdated (405) connect failure (client version: %s)", store.GetWAVersion().String())
+		cli.Log.Errorf("Client outdated (405) connect failure (client version: %s)", cli.Store.GetWAVersion().String())
 		go cli.dispatchEvent(&events.ClientOutdated{})
 	} else if reason == events.ConnectFailureCATInvalid || reason == events.ConnectFailureCATExpired {
 		cli.Log.Infof("Got %d/%s connect failure, refreshing CAT before reconnecting...", int(reason), message)
@@ -142,6 +154,10 @@ func (cli *Client) handleConnectFailure(node *waBinary.Node) {
 			cli.expectDisconnect()
 			go cli.dispatchEvent(&events.CATRefreshError{Error: err})
 		}
+	} else if reason == events.ConnectFailureServiceUnavailable {
+		retryAfter := cli.setServerReconnectDelay(node)
+		cli.Log.Warnf("Got 503/%s connect failure (retry after: %s), assuming automatic reconnect will handle it", message, retryAfter)
+		go cli.dispatchEvent(&events.ServiceUnavailable{OnConnect: true, RetryAfter: retryAfter, Message: message, Raw: node})
 	} else if willAutoReconnect {
 		cli.Log.Warnf("Got %d/%s connect failure, assuming automatic reconnect will handle it", int(reason), message)
 	} else {
@@ -191,8 +207,19 @@ func (cli *Client) handleConnectSuccess(node *waBinary.Node) {
 		if err != nil {
 			cli.Log.Warnf("Failed to send post-connect passive IQ: %v", err)
 		}
+		if cli.AutoSendPresence {
+			if len(cli.Store.PushName) == 0 && cli.MessengerConfig == nil {
+				cli.Log.Debugf("Not sending automatic presence after connecting as push name is not known yet")
+			} else if err = cli.SendPresence(types.PresenceAvailable); err != nil {
+				cli.Log.Warnf("Failed to send automatic presence after connecting: %v", err)
+			}
+		}
 		cli.dispatchEvent(&events.Connected{})
 		cli.closeSocketWaitChan()
+		if err = cli.Store.Idempotency.DeleteOldIdempotencyKeys(ctx); err != nil {
+			cli.Log.Warnf("Failed to delete old idempotency keys: %v", err)
+		}
+		cli.flushOutbox(cli.BackgroundEventCtx)
 	}()
 }
 
@@ -0,0 +1,168 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// GetContextInfo returns the ContextInfo of the given message, or nil if the message type doesn't have one
+// or if it isn't set. Wrapper messages like EphemeralMessage are not unwrapped automatically.
+func GetContextInfo(msg *waE2E.Message) *waE2E.ContextInfo {
+	switch {
+	case msg.GetExtendedTextMessage() != nil:
+		return msg.GetExtendedTextMessage().GetContextInfo()
+	case msg.GetImageMessage() != nil:
+		return msg.GetImageMessage().GetContextInfo()
+	case msg.GetVideoMessage() != nil:
+		return msg.GetVideoMessage().GetContextInfo()
+	case msg.GetAudioMessage() != nil:
+		return msg.GetAudioMessage().GetContextInfo()
+	case msg.GetDocumentMessage() != nil:
+		return msg.GetDocumentMessage().GetContextInfo()
+	case msg.GetStickerMessage() != nil:
+		return msg.GetStickerMessage().GetContextInfo()
+	case msg.GetLocationMessage() != nil:
+		return msg.GetLocationMessage().GetContextInfo()
+	case msg.GetLiveLocationMessage() != nil:
+		return msg.GetLiveLocationMessage().GetContextInfo()
+	case msg.GetContactMessage() != nil:
+		return msg.GetContactMessage().GetContextInfo()
+	case msg.GetContactsArrayMessage() != nil:
+		return msg.GetContactsArrayMessage().GetContextInfo()
+	case msg.GetPollCreationMessage() != nil:
+		return msg.GetPollCreationMessage().GetContextInfo()
+	case msg.GetEventMessage() != nil:
+		return msg.GetEventMessage().GetContextInfo()
+	default:
+		return nil
+	}
+}
+
+// GetOrCreateContextInfo returns the ContextInfo of the given message, creating it if necessary.
+// Plain Conversation messages are converted into ExtendedTextMessages, as they can't have context info.
+// If the message type doesn't support context info, this returns nil.
+func GetOrCreateContextInfo(msg *waE2E.Message) *waE2E.ContextInfo {
+	return getOrCreateContextInfo(msg)
+}
+
+// SetExpiration sets the disappearing message timer in the given context info.
+// The setAt parameter is the time when the chat's disappearing timer was last changed and may be zero.
+func SetExpiration(ctxInfo *waE2E.ContextInfo, timer time.Duration, setAt time.Time) {
+	ctxInfo.Expiration = proto.Uint32(uint32(timer.Seconds()))
+	if !setAt.IsZero() {
+		ctxInfo.EphemeralSettingTimestamp = proto.Int64(setAt.Unix())
+	}
+}
+
+// GetExpiration returns the disappearing message timer from the given context info,
+// along with the time when the chat's timer was last changed (zero if not known).
+func GetExpiration(ctxInfo *waE2E.ContextInfo) (timer time.Duration, setAt time.Time) {
+	timer = time.Duration(ctxInfo.GetExpiration()) * time.Second
+	if ctxInfo.EphemeralSettingTimestamp != nil {
+		setAt = time.Unix(ctxInfo.GetEphemeralSettingTimestamp(), 0)
+	}
+	return
+}
+
+// ForwardedNewsletterInfo contains the info about the original channel of a message forwarded from a WhatsApp channel.
+type ForwardedNewsletterInfo struct {
+	JID      types.JID
+	ServerID types.MessageServerID
+	Name     string
+}
+
+// SetForwardedNewsletter marks the given context info as forwarded from the given WhatsApp channel message.
+func SetForwardedNewsletter(ctxInfo *waE2E.ContextInfo, info ForwardedNewsletterInfo) {
+	ctxInfo.IsForwarded = proto.Bool(true)
+	ctxInfo.ForwardingScore = proto.Uint32(max(ctxInfo.GetForwardingScore(), 1))
+	ctxInfo.ForwardedNewsletterMessageInfo = &waE2E.ContextInfo_ForwardedNewsletterMessageInfo{
+		NewsletterJID:   proto.String(info.JID.String()),
+		ServerMessageID: proto.Int32(int32(info.ServerID)),
+		NewsletterName:  proto.String(info.Name),
+	}
+}
+
+// GetForwardedNewsletter returns the info about the channel the message was forwarded from,
+// or nil if the message wasn't forwarded from a channel.
+func GetForwardedNewsletter(ctxInfo *waE2E.ContextInfo) *ForwardedNewsletterInfo {
+	fwd := ctxInfo.GetForwardedNewsletterMessageInfo()
+	if fwd == nil {
+		return nil
+	}
+	jid, _ := types.ParseJID(fwd.GetNewsletterJID())
+	return &ForwardedNewsletterInfo{
+		JID:      jid,
+		ServerID: types.MessageServerID(fwd.GetServerMessageID()),
+		Name:     fwd.GetNewsletterName(),
+	}
+}
+
+// ExternalAdReply contains the commonly used fields of the external ad reply preview in ContextInfo,
+// which is rendered as a link preview card above the message.
+type ExternalAdReply struct {
+	Title        string
+	Body         string
+	SourceURL    string // The URL opened when clicking the preview
+	ThumbnailURL string
+	Thumbnail    []byte // A JPEG thumbnail, used if ThumbnailURL is not set
+	MediaType    waE2E.ContextInfo_ExternalAdReplyInfo_MediaType
+
+	RenderLargerThumbnail bool
+	ShowAdAttribution     bool
+}
+
+// Proto converts the external ad reply into the protobuf struct.
+func (ear *ExternalAdReply) Proto() *waE2E.ContextInfo_ExternalAdReplyInfo {
+	info := &waE2E.ContextInfo_ExternalAdReplyInfo{
+		Title:     proto.String(ear.Title),
+		Body:      proto.String(ear.Body),
+		MediaType: ear.MediaType.Enum(),
+		Thumbnail: ear.Thumbnail,
+	}
+	if ear.SourceURL != "" {
+		info.SourceURL = proto.String(ear.SourceURL)
+	}
+	if ear.ThumbnailURL != "" {
+		info.ThumbnailURL = proto.String(ear.ThumbnailURL)
+	}
+	if ear.RenderLargerThumbnail {
+		info.RenderLargerThumbnail = proto.Bool(true)
+	}
+	if ear.ShowAdAttribution {
+		info.ShowAdAttribution = proto.Bool(true)
+	}
+	return info
+}
+
+// SetExternalAdReply sets the external ad reply preview in the given context info.
+func SetExternalAdReply(ctxInfo *waE2E.ContextInfo, ear *ExternalAdReply) {
+	ctxInfo.ExternalAdReply = ear.Proto()
+}
+
+// GetExternalAdReply returns the external ad reply preview from the given context info, or nil if there isn't one.
+func GetExternalAdReply(ctxInfo *waE2E.ContextInfo) *ExternalAdReply {
+	info := ctxInfo.GetExternalAdReply()
+	if info == nil {
+		return nil
+	}
+	return &ExternalAdReply{
+		Title:                 info.GetTitle(),
+		Body:                  info.GetBody(),
+		SourceURL:             info.GetSourceURL(),
+		ThumbnailURL:          info.GetThumbnailURL(),
+		Thumbnail:             info.GetThumbnail(),
+		MediaType:             info.GetMediaType(),
+		RenderLargerThumbnail: info.GetRenderLargerThumbnail(),
+		ShowAdAttribution:     info.GetShowAdAttribution(),
+	}
+}
@@ -8,6 +8,7 @@ package whatsmeow
 
 import (
 	"context"
+	"crypto/aes"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/base64"
@@ -16,7 +17,10 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"go.mau.fi/util/retryafter"
@@ -294,7 +298,7 @@ func (cli *Client) downloadAndDecrypt(
 ) (data []byte, err error) {
 	iv, cipherKey, macKey, _ := getMediaKeys(mediaKey, appInfo)
 	var ciphertext, mac []byte
-	if ciphertext, mac, err = cli.downloadPossiblyEncryptedMediaWithRetries(ctx, url, fileEncSHA256); err != nil {
+	if ciphertext, mac, err = cli.downloadPossiblyEncryptedMediaWithRetries(ctx, url, fileEncSHA256, getMaxDownloadSize(fileLength, mediaKey != nil || fileEncSHA256 != nil)); err != nil {
 
 	} else if mediaKey == nil && fileEncSHA256 == nil && mac == nil {
 		// Unencrypted media, just return the downloaded data
@@ -313,6 +317,18 @@ func (cli *Client) downloadAndDecrypt(
 	return
 }
 
+// getMaxDownloadSize returns the maximum size of the downloaded file based on the plaintext length in the message,
+// or -1 if the length is not known.
+func getMaxDownloadSize(fileLength int, encrypted bool) int64 {
+	if fileLength <= 0 {
+		return -1
+	} else if !encrypted {
+		return int64(fileLength)
+	}
+	// CBC padding always adds 1-16 bytes, plus the truncated HMAC at the end
+	return int64(fileLength/aes.BlockSize+1)*aes.BlockSize + mediaHMACLength
+}
+
 func getMediaKeys(mediaKey []byte, appInfo MediaType) (iv, cipherKey, macKey, refKey []byte) {
 	mediaKeyExpanded := hkdfutil.SHA256(mediaKey, nil, []byte(appInfo), 112)
 	return mediaKeyExpanded[:16], mediaKeyExpanded[16:48], mediaKeyExpanded[48:80], mediaKeyExpanded[80:]
@@ -329,12 +345,12 @@ func shouldRetryMediaDownload(err error) bool {
 		(errors.As(err, &httpErr) && retryafter.Should(httpErr.StatusCode, true))
 }
 
-func (cli *Client) downloadPossiblyEncryptedMediaWithRetries(ctx context.Context, url string, checksum []byte) (file, mac []byte, err error) {
+func (cli *Client) downloadPossiblyEncryptedMediaWithRetries(ctx context.Context, url string, checksum []byte, maxSize int64) (file, mac []byte, err error) {
 	for retryNum := 0; retryNum < 5; retryNum++ {
 		if checksum == nil {
-			file, err = cli.downloadMedia(ctx, url)
+			file, err = cli.downloadMedia(ctx, url, maxSize)
 		} else {
-			file, mac, err = cli.downloadEncryptedMedia(ctx, url, checksum)
+			file, mac, err = cli.downloadEncryptedMedia(ctx, url, checksum, maxSize)
 		}
 		if err == nil || !shouldRetryMediaDownload(err) {
 			return
@@ -355,10 +371,17 @@ func (cli *Client) downloadPossiblyEncryptedMediaWithRetries(ctx context.Context
 }
 
 func (cli *Client) doMediaDownloadRequest(ctx context.Context, url string) (*http.Response, error) {
+	return cli.doMediaRangeRequest(ctx, url, "")
+}
+
+func (cli *Client) doMediaRangeRequest(ctx context.Context, url, byteRange string) (*http.Response, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to prepare request: %w", err)
 	}
+	if byteRange != "" {
+		req.Header.Set("Range", byteRange)
+	}
 	req.Header.Set("Origin", socket.Origin)
 	req.Header.Set("Referer", socket.Origin+"/")
 	if cli.MessengerConfig != nil {
@@ -369,27 +392,48 @@ func (cli *Client) doMediaDownloadRequest(ctx context.Context, url string) (*htt
 	if err != nil {
 		return nil, err
 	}
-	if resp.StatusCode != http.StatusOK {
+	if resp.StatusCode != http.StatusOK && (byteRange == "" || resp.StatusCode != http.StatusPartialContent) {
 		_ = resp.Body.Close()
 		return nil, DownloadHTTPError{Response: resp}
 	}
 	return resp, nil
 }
 
-func (cli *Client) downloadMedia(ctx context.Context, url string) ([]byte, error) {
+// downloadMedia downloads the given URL. If maxSize is not negative, files larger than that are rejected
+// with ErrFileLengthMismatch instead of being read into memory.
+//
+// Parallel downloads allocate a buffer for the whole size reported by the server upfront,
+// so they're only used when maxSize is known.
+func (cli *Client) downloadMedia(ctx context.Context, url string, maxSize int64) ([]byte, error) {
+	if cli.ParallelMediaDownloads > 1 && maxSize >= 0 {
+		return cli.downloadMediaParallel(ctx, url, maxSize)
+	}
 	resp, err := cli.doMediaDownloadRequest(ctx, url)
 	if err != nil {
 		return nil, err
 	}
-	data, err := io.ReadAll(resp.Body)
-	_ = resp.Body.Close()
-	return data, err
+	defer resp.Body.Close()
+	return readMediaBody(resp.Body, maxSize)
+}
+
+// readMediaBody reads the whole response body, but fails if it's larger than maxSize (unless maxSize is negative).
+func readMediaBody(body io.Reader, maxSize int64) ([]byte, error) {
+	if maxSize < 0 {
+		return io.ReadAll(body)
+	}
+	data, err := io.ReadAll(io.LimitReader(body, maxSize+1))
+	if err != nil {
+		return nil, err
+	} else if int64(len(data)) > maxSize {
+		return nil, fmt.Errorf("%w: server sent more than %d bytes", ErrFileLengthMismatch, maxSize)
+	}
+	return data, nil
 }
 
 const mediaHMACLength = 10
 
-func (cli *Client) downloadEncryptedMedia(ctx context.Context, url string, checksum []byte) (file, mac []byte, err error) {
-	data, err := cli.downloadMedia(ctx, url)
+func (cli *Client) downloadEncryptedMedia(ctx context.Context, url string, checksum []byte, maxSize int64) (file, mac []byte, err error) {
+	data, err := cli.downloadMedia(ctx, url, maxSize)
 	if err != nil {
 		return
 	} else if len(data) <= mediaHMACLength {
@@ -412,3 +456,86 @@ func validateMedia(iv, file, macKey, mac []byte) error {
 	}
 	return nil
 }
+
+const (
+	// The size of the first range request in parallel downloads, which is used to find the total size.
+	parallelDownloadFirstChunk = 1024 * 1024
+	// The maximum total size accepted from the Content-Range header in parallel downloads.
+	maxParallelDownloadSize = 4 * 1024 * 1024 * 1024
+)
+
+var contentRangeRegex = regexp.MustCompile(`^bytes 0-\d+/(\d+)$`)
+
+func (cli *Client) downloadMediaParallel(ctx context.Context, url string, maxSize int64) ([]byte, error) {
+	resp, err := cli.doMediaRangeRequest(ctx, url, fmt.Sprintf("bytes=0-%d", parallelDownloadFirstChunk-1))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		// The server doesn't support range requests, so it sent the whole file
+		return readMediaBody(resp.Body, maxSize)
+	}
+	match := contentRangeRegex.FindStringSubmatch(resp.Header.Get("Content-Range"))
+	if match == nil {
+		return nil, fmt.Errorf("unexpected Content-Range header %q", resp.Header.Get("Content-Range"))
+	}
+	totalSize, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil || totalSize > maxParallelDownloadSize {
+		return nil, fmt.Errorf("invalid total size in Content-Range header %q", resp.Header.Get("Content-Range"))
+	} else if totalSize > maxSize {
+		return nil, fmt.Errorf("%w: server reported %d bytes, expected at most %d", ErrFileLengthMismatch, totalSize, maxSize)
+	}
+	data := make([]byte, totalSize)
+	firstChunkSize := min(totalSize, parallelDownloadFirstChunk)
+	if _, err = io.ReadFull(resp.Body, data[:firstChunkSize]); err != nil {
+		return nil, err
+	}
+	_ = resp.Body.Close()
+	remaining := totalSize - firstChunkSize
+	if remaining <= 0 {
+		return data, nil
+	}
+	parts := int64(cli.ParallelMediaDownloads)
+	partSize := (remaining + parts - 1) / parts
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var wg sync.WaitGroup
+	var errLock sync.Mutex
+	var firstErr error
+	for start := firstChunkSize; start < totalSize; start += partSize {
+		end := min(start+partSize, totalSize)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := cli.downloadMediaRange(ctx, url, data[start:end], start)
+			if err != nil {
+				errLock.Lock()
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				errLock.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return data, nil
+}
+
+func (cli *Client) downloadMediaRange(ctx context.Context, url string, into []byte, start int64) error {
+	resp, err := cli.doMediaRangeRequest(ctx, url, fmt.Sprintf("bytes=%d-%d", start, start+int64(len(into))-1))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusPartialContent {
+		return DownloadHTTPError{Response: resp}
+	}
+	// Errors are returned as-is like in non-parallel downloads, so that shouldRetryMediaDownload can classify them
+	_, err = io.ReadFull(resp.Body, into)
+	return err
+}
@@ -36,6 +36,9 @@ var (
 	ErrNoPrivacyToken = errors.New("no privacy token stored")
 
 	ErrAppStateUpdate = errors.New("server returned error updating app state")
+	// ErrAppStateVersionConflict is returned by SendAppState (along with ErrAppStateUpdate) if the server
+	// still rejected the patch due to a version conflict after resyncing the app state.
+	ErrAppStateVersionConflict = errors.New("app state version conflict")
 )
 
 // Errors that happen while confirming device pairing
@@ -90,6 +93,8 @@ var (
 	ErrInviteLinkInvalid = errors.New("that group invite link is not valid")
 	// ErrInviteLinkRevoked is returned by methods that use group invite links if the invite link was valid, but has been revoked and can no longer be used.
 	ErrInviteLinkRevoked = errors.New("that group invite link has been revoked")
+	// ErrGroupInviteNotNeeded is returned by AddParticipantOrBuildInvite if the user was added to the group directly.
+	ErrGroupInviteNotNeeded = errors.New("the user was added to the group directly, an invite is not needed")
 	// ErrBusinessMessageLinkNotFound is returned by ResolveBusinessMessageLink if the link doesn't exist or has been revoked.
 	ErrBusinessMessageLinkNotFound = errors.New("that business message link does not exist or has been revoked")
 	// ErrContactQRLinkNotFound is returned by ResolveContactQRLink if the link doesn't exist or has been revoked.
@@ -100,6 +105,8 @@ var (
 	ErrMediaNotAvailableOnPhone = errors.New("media no longer available on phone")
 	// ErrUnknownMediaRetryError is returned by DecryptMediaRetryNotification if the given event contains an unknown error code.
 	ErrUnknownMediaRetryError = errors.New("unknown media retry error")
+	// ErrMediaReuploadFailed is returned by RequestMediaReupload if the phone responded with a non-success result.
+	ErrMediaReuploadFailed = errors.New("media re-upload failed")
 	// ErrInvalidDisappearingTimer is returned by SetDisappearingTimer if the given timer is not one of the allowed values.
 	ErrInvalidDisappearingTimer = errors.New("invalid disappearing timer provided")
 )
@@ -111,6 +118,7 @@ var (
 	ErrRecipientADJID           = errors.New("message recipient must be a user JID with no device part")
 	ErrServerReturnedError      = errors.New("server returned error")
 	ErrInvalidInlineBotID       = errors.New("invalid inline bot ID")
+	ErrIdempotencyKeyReused     = errors.New("idempotency key was already used for a message to another chat")
 )
 
 type DownloadHTTPError struct {
@@ -146,6 +154,7 @@ var (
 	ErrNotEncryptedReactionMessage   = errors.New("given message isn't an encrypted reaction message")
 	ErrNotEncryptedCommentMessage    = errors.New("given message isn't an encrypted comment message")
 	ErrNotPollUpdateMessage          = errors.New("given message isn't a poll update message")
+	ErrNotEncryptedEventResponse     = errors.New("given message isn't an encrypted event response message")
 )
 
 type wrappedIQError struct {
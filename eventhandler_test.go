@@ -0,0 +1,148 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.mau.fi/whatsmeow/store"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+func newTestClient() *Client {
+	return NewClient(&store.Device{}, nil)
+}
+
+func countEventHandlers(cli *Client) int {
+	cli.eventHandlersLock.RLock()
+	defer cli.eventHandlersLock.RUnlock()
+	return len(cli.eventHandlers)
+}
+
+// dispatchConcurrently dispatches the given event from many goroutines at once and waits for all of them.
+func dispatchConcurrently(cli *Client, evt any) {
+	const goroutines = 64
+	var start, wg sync.WaitGroup
+	start.Add(1)
+	wg.Add(goroutines)
+	for range goroutines {
+		go func() {
+			defer wg.Done()
+			start.Wait()
+			cli.dispatchEvent(evt)
+		}()
+	}
+	start.Done()
+	wg.Wait()
+}
+
+func TestAddEventHandlerOnceConcurrent(t *testing.T) {
+	for range 20 {
+		cli := newTestClient()
+		var calls, calledAfterDone atomic.Int32
+		var done atomic.Bool
+		cli.AddEventHandlerOnce(func(evt any) bool {
+			if done.Load() {
+				calledAfterDone.Add(1)
+			}
+			calls.Add(1)
+			// Give other dispatches time to call the handler before it returns
+			time.Sleep(time.Millisecond)
+			done.Store(true)
+			return true
+		})
+		dispatchConcurrently(cli, &events.Connected{})
+		if calls.Load() != 1 || calledAfterDone.Load() != 0 {
+			t.Fatalf("expected handler to be called once, got %d calls (%d after returning true)", calls.Load(), calledAfterDone.Load())
+		}
+		if countEventHandlers(cli) != 0 {
+			t.Fatalf("expected handler to be removed, %d handlers remain", countEventHandlers(cli))
+		}
+	}
+}
+
+func TestAddEventHandlerOnceIgnoresEvents(t *testing.T) {
+	cli := newTestClient()
+	var calls int
+	cli.AddEventHandlerOnce(func(evt any) bool {
+		calls++
+		_, ok := evt.(*events.Connected)
+		return ok
+	})
+	cli.dispatchEvent(&events.Disconnected{})
+	cli.dispatchEvent(&events.Disconnected{})
+	cli.dispatchEvent(&events.Connected{})
+	cli.dispatchEvent(&events.Connected{})
+	if calls != 3 {
+		t.Fatalf("expected handler to be called 3 times, got %d", calls)
+	}
+}
+
+func TestRemoveEventHandlerDuringDispatch(t *testing.T) {
+	cli := newTestClient()
+	var secondID uint32
+	var firstCalls, secondCalls, thirdCalls int
+	cli.AddEventHandler(func(evt any) {
+		firstCalls++
+		cli.RemoveEventHandler(secondID)
+	})
+	secondID = cli.AddEventHandler(func(evt any) {
+		secondCalls++
+	})
+	cli.AddEventHandler(func(evt any) {
+		thirdCalls++
+	})
+	// The handler list being dispatched to isn't modified, so the removed handler still receives the current event
+	cli.dispatchEvent(&events.Connected{})
+	cli.dispatchEvent(&events.Connected{})
+	if firstCalls != 2 || thirdCalls != 2 {
+		t.Errorf("expected other handlers to receive both events, got %d and %d", firstCalls, thirdCalls)
+	}
+	if secondCalls != 1 {
+		t.Errorf("expected removed handler to only receive the event being dispatched, got %d", secondCalls)
+	}
+}
+
+func TestWaitForEventConcurrent(t *testing.T) {
+	cli := newTestClient()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	result := make(chan *events.Connected, 1)
+	go func() {
+		evt, err := WaitForEvent[*events.Connected](ctx, cli)
+		if err != nil {
+			t.Errorf("failed to wait for event: %v", err)
+		}
+		result <- evt
+	}()
+	for countEventHandlers(cli) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	evt := &events.Connected{}
+	// None of the dispatches may block even though only one event can be received
+	dispatchConcurrently(cli, evt)
+	if received := <-result; received != evt {
+		t.Errorf("received unexpected event %v", received)
+	}
+}
+
+func TestWaitForEventCancel(t *testing.T) {
+	cli := newTestClient()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := WaitForEvent[*events.Connected](ctx, cli)
+	if err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if countEventHandlers(cli) != 0 {
+		t.Fatalf("expected handler to be removed, %d handlers remain", countEventHandlers(cli))
+	}
+}
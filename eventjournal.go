@@ -0,0 +1,113 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// JournalEntry is a single event stored in an EventJournal.
+type JournalEntry struct {
+	Seq   uint64
+	Time  time.Time
+	Event any
+}
+
+// EventJournal is an append-only log of dispatched events, which can be set in Client.EventJournal.
+//
+// Every event is appended to the journal before it's passed to event handlers, and marked as handled
+// once all handlers have returned successfully (see AddEventHandlerWithSuccessStatus). Events that were
+// never marked as handled, e.g. because the process crashed mid-processing, can be dispatched again
+// using Client.ReplayEvents.
+//
+// Implementations that persist the journal across restarts are responsible for serializing the events.
+type EventJournal interface {
+	// Append stores the given event and returns a sequence number identifying it.
+	// Sequence numbers must be increasing.
+	Append(ctx context.Context, evt any) (uint64, error)
+	// MarkHandled marks the event with the given sequence number as successfully handled.
+	MarkHandled(ctx context.Context, seq uint64) error
+	// GetUnhandled returns all events that haven't been marked as handled, ordered by sequence number.
+	GetUnhandled(ctx context.Context) ([]*JournalEntry, error)
+}
+
+// MemoryEventJournal is a simple in-memory EventJournal implementation.
+//
+// It can be used to replay events that handlers failed to process, but it obviously doesn't survive restarts.
+type MemoryEventJournal struct {
+	lock    sync.Mutex
+	nextSeq uint64
+	entries []*JournalEntry
+}
+
+var _ EventJournal = (*MemoryEventJournal)(nil)
+
+// NewMemoryEventJournal creates a new in-memory event journal.
+func NewMemoryEventJournal() *MemoryEventJournal {
+	return &MemoryEventJournal{nextSeq: 1}
+}
+
+func (mej *MemoryEventJournal) Append(_ context.Context, evt any) (uint64, error) {
+	mej.lock.Lock()
+	defer mej.lock.Unlock()
+	seq := mej.nextSeq
+	mej.nextSeq++
+	mej.entries = append(mej.entries, &JournalEntry{Seq: seq, Time: time.Now(), Event: evt})
+	return seq, nil
+}
+
+func (mej *MemoryEventJournal) MarkHandled(_ context.Context, seq uint64) error {
+	mej.lock.Lock()
+	defer mej.lock.Unlock()
+	for i, entry := range mej.entries {
+		if entry.Seq == seq {
+			mej.entries = append(mej.entries[:i], mej.entries[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (mej *MemoryEventJournal) GetUnhandled(_ context.Context) ([]*JournalEntry, error) {
+	mej.lock.Lock()
+	defer mej.lock.Unlock()
+	entries := make([]*JournalEntry, len(mej.entries))
+	copy(entries, mej.entries)
+	return entries, nil
+}
+
+// ReplayEvents dispatches all events in the EventJournal that haven't been marked as handled.
+// Events that are handled successfully this time are marked as handled.
+//
+// This should usually be called after adding event handlers, but before connecting.
+// It returns the number of events that were replayed.
+func (cli *Client) ReplayEvents(ctx context.Context) (int, error) {
+	if cli == nil {
+		return 0, ErrClientIsNil
+	} else if cli.EventJournal == nil {
+		return 0, nil
+	}
+	entries, err := cli.EventJournal.GetUnhandled(ctx)
+	if err != nil {
+		return 0, err
+	}
+	for i, entry := range entries {
+		if ctx.Err() != nil {
+			return i, ctx.Err()
+		}
+		cli.Log.Debugf("Replaying journaled %T #%d from %s", entry.Event, entry.Seq, entry.Time)
+		if !cli.dispatchEventToHandlers(entry.Event) {
+			err = cli.EventJournal.MarkHandled(ctx, entry.Seq)
+			if err != nil {
+				return i + 1, err
+			}
+		}
+	}
+	return len(entries), nil
+}
@@ -8,6 +8,7 @@ require (
 	github.com/beeper/argo-go v1.1.2
 	github.com/google/uuid v1.6.0
 	github.com/gorilla/websocket v1.5.0
+	github.com/mattn/go-sqlite3 v1.14.32
 	github.com/rs/zerolog v1.34.0
 	go.mau.fi/libsignal v0.2.0
 	go.mau.fi/util v0.9.0
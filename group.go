@@ -11,8 +11,12 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
 
 	waBinary "go.mau.fi/whatsmeow/binary"
+	"go.mau.fi/whatsmeow/proto/waE2E"
 	"go.mau.fi/whatsmeow/store"
 	"go.mau.fi/whatsmeow/types"
 	"go.mau.fi/whatsmeow/types/events"
@@ -188,6 +192,10 @@ const (
 
 // UpdateGroupParticipants can be used to add, remove, promote and demote members in a WhatsApp group.
 func (cli *Client) UpdateGroupParticipants(jid types.JID, participantChanges []types.JID, action ParticipantChange) ([]types.GroupParticipant, error) {
+	return cli.updateGroupParticipants(context.TODO(), jid, participantChanges, action)
+}
+
+func (cli *Client) updateGroupParticipants(ctx context.Context, jid types.JID, participantChanges []types.JID, action ParticipantChange) ([]types.GroupParticipant, error) {
 	content := make([]waBinary.Node, len(participantChanges))
 	for i, participantJID := range participantChanges {
 		content[i] = waBinary.Node{
@@ -195,7 +203,7 @@ func (cli *Client) UpdateGroupParticipants(jid types.JID, participantChanges []t
 			Attrs: waBinary.Attrs{"jid": participantJID},
 		}
 	}
-	resp, err := cli.sendGroupIQ(context.TODO(), iqSet, jid, waBinary.Node{
+	resp, err := cli.sendGroupIQ(ctx, iqSet, jid, waBinary.Node{
 		Tag:     string(action),
 		Content: content,
 	})
@@ -445,6 +453,83 @@ func (cli *Client) JoinGroupWithInvite(jid, inviter types.JID, code string, expi
 	return err
 }
 
+// JoinGroupWithInviteMessage joins a group using a received invite message.
+//
+// This is a convenience wrapper for JoinGroupWithInvite. The inviter should be the sender of the message,
+// i.e. evt.Info.Sender when handling an *events.Message that contains a GroupInviteMessage.
+func (cli *Client) JoinGroupWithInviteMessage(msg *waE2E.GroupInviteMessage, inviter types.JID) error {
+	jid, err := types.ParseJID(msg.GetGroupJID())
+	if err != nil {
+		return fmt.Errorf("failed to parse group JID in invite message: %w", err)
+	}
+	return cli.JoinGroupWithInvite(jid, inviter, msg.GetInviteCode(), msg.GetInviteExpiration())
+}
+
+// AddParticipantOrBuildInvite adds the given user to the group, or builds a group invite message for them
+// if their privacy settings don't allow adding them directly. The invite message can be sent to the user
+// privately using Client.SendMessage.
+//
+// Unlike the Build* methods, this changes the group: if the user can be added directly, they will be added
+// and ErrGroupInviteNotNeeded is returned instead of a message.
+//
+//	msg, err := cli.AddParticipantOrBuildInvite(ctx, groupJID, userJID, "Join my group!")
+//	if err == nil {
+//		resp, err = cli.SendMessage(ctx, userJID, msg)
+//	}
+//
+// If you already have an invite code (e.g. from the AddRequest field of the participants returned by
+// CreateGroup or UpdateGroupParticipants), use BuildGroupInviteMessageWithCode instead.
+func (cli *Client) AddParticipantOrBuildInvite(ctx context.Context, jid, user types.JID, caption string) (*waE2E.Message, error) {
+	participants, err := cli.updateGroupParticipants(ctx, jid, []types.JID{user}, ParticipantChangeAdd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invite code: %w", err)
+	}
+	for _, participant := range participants {
+		if participant.JID.User != user.User && participant.PhoneNumber.User != user.User && participant.LID.User != user.User {
+			continue
+		} else if participant.Error == 0 {
+			return nil, ErrGroupInviteNotNeeded
+		} else if participant.AddRequest == nil {
+			return nil, fmt.Errorf("adding user to group failed with error %d without an invite code", participant.Error)
+		}
+		return cli.BuildGroupInviteMessageWithCode(ctx, jid, participant.AddRequest.Code, participant.AddRequest.Expiration, caption)
+	}
+	return nil, &ElementMissingError{Tag: "participant", In: "response to group participants update"}
+}
+
+// BuildGroupInviteMessageWithCode builds a group invite message using an existing invite code.
+// The group name and photo thumbnail are fetched from the server automatically.
+// The built message can be sent normally using Client.SendMessage.
+func (cli *Client) BuildGroupInviteMessageWithCode(ctx context.Context, jid types.JID, code string, expiration time.Time, caption string) (*waE2E.Message, error) {
+	info, err := cli.getGroupInfo(ctx, jid, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group info: %w", err)
+	}
+	msg := &waE2E.GroupInviteMessage{
+		GroupJID:         proto.String(jid.String()),
+		InviteCode:       proto.String(code),
+		InviteExpiration: proto.Int64(expiration.Unix()),
+		GroupName:        proto.String(info.Name),
+		GroupType:        waE2E.GroupInviteMessage_DEFAULT.Enum(),
+	}
+	if caption != "" {
+		msg.Caption = proto.String(caption)
+	}
+	if info.IsParent {
+		msg.GroupType = waE2E.GroupInviteMessage_PARENT.Enum()
+	}
+	picture, err := cli.GetProfilePictureInfo(jid, &GetProfilePictureParams{Preview: true, IsCommunity: info.IsParent})
+	if err != nil && !errors.Is(err, ErrProfilePictureNotSet) {
+		cli.Log.Warnf("Failed to get picture of %s for invite message: %v", jid, err)
+	} else if picture != nil && picture.URL != "" {
+		msg.JPEGThumbnail, err = cli.downloadMedia(ctx, picture.URL, -1)
+		if err != nil {
+			cli.Log.Warnf("Failed to download picture of %s for invite message: %v", jid, err)
+		}
+	}
+	return &waE2E.Message{GroupInviteMessage: msg}, nil
+}
+
 // GetGroupInfoFromLink resolves the given invite link and asks the WhatsApp servers for info about the group.
 // This will not cause the user to join the group.
 func (cli *Client) GetGroupInfoFromLink(code string) (*types.GroupInfo, error) {
@@ -494,7 +579,12 @@ func (cli *Client) JoinGroupWithLink(code string) (types.JID, error) {
 
 // GetJoinedGroups returns the list of groups the user is participating in.
 func (cli *Client) GetJoinedGroups() ([]*types.GroupInfo, error) {
-	resp, err := cli.sendGroupIQ(context.TODO(), iqGet, types.GroupServerJID, waBinary.Node{
+	return cli.GetJoinedGroupsContext(context.TODO())
+}
+
+// GetJoinedGroupsContext is like GetJoinedGroups, but the given context can be used to cancel the query.
+func (cli *Client) GetJoinedGroupsContext(ctx context.Context) ([]*types.GroupInfo, error) {
+	resp, err := cli.sendGroupIQ(ctx, iqGet, types.GroupServerJID, waBinary.Node{
 		Tag: "participating",
 		Content: []waBinary.Node{
 			{Tag: "participants"},
@@ -569,7 +659,12 @@ func (cli *Client) GetLinkedGroupsParticipants(community types.JID) ([]types.JID
 
 // GetGroupInfo requests basic info about a group chat from the WhatsApp servers.
 func (cli *Client) GetGroupInfo(jid types.JID) (*types.GroupInfo, error) {
-	return cli.getGroupInfo(context.TODO(), jid, true)
+	return cli.GetGroupInfoContext(context.TODO(), jid)
+}
+
+// GetGroupInfoContext is like GetGroupInfo, but the given context can be used to cancel the query.
+func (cli *Client) GetGroupInfoContext(ctx context.Context, jid types.JID) (*types.GroupInfo, error) {
+	return cli.getGroupInfo(ctx, jid, true)
 }
 
 func (cli *Client) getGroupInfo(ctx context.Context, jid types.JID, lockParticipantCache bool) (*types.GroupInfo, error) {
@@ -680,6 +775,7 @@ func (cli *Client) parseGroupNode(groupNode *waBinary.Node) (*types.GroupInfo, e
 
 	group.AnnounceVersionID = ag.OptionalString("a_v_id")
 	group.ParticipantVersionID = ag.OptionalString("p_v_id")
+	group.ParticipantCount = ag.OptionalInt("size")
 	group.AddressingMode = types.AddressingMode(ag.OptionalString("addressing_mode"))
 
 	for _, child := range groupNode.GetChildren() {
@@ -748,11 +844,15 @@ func parseGroupLinkTargetNode(groupNode *waBinary.Node) (types.GroupLinkTarget,
 }
 
 func parseParticipantList(node *waBinary.Node) (participants []types.JID, lidPairs []store.LIDMapping) {
+	return parseParticipantListWithTag(node, "participant")
+}
+
+func parseParticipantListWithTag(node *waBinary.Node, tag string) (participants []types.JID, lidPairs []store.LIDMapping) {
 	children := node.GetChildren()
 	participants = make([]types.JID, 0, len(children))
 	for _, child := range children {
 		jid, ok := child.Attrs["jid"].(types.JID)
-		if child.Tag != "participant" || !ok {
+		if child.Tag != tag || !ok {
 			continue
 		}
 		participants = append(participants, jid)
@@ -924,6 +1024,15 @@ func (cli *Client) parseGroupChange(node *waBinary.Node) (*events.GroupInfo, []s
 			evt.MembershipApprovalMode = &types.GroupMembershipApprovalMode{
 				IsJoinApprovalRequired: true,
 			}
+			// Older notifications don't include the state, in which case approval mode is assumed to be enabled
+			if groupJoin, ok := child.GetOptionalChildByTag("group_join"); ok {
+				evt.MembershipApprovalMode.IsJoinApprovalRequired = groupJoin.AttrGetter().OptionalString("state") == "on"
+			}
+		case "created_membership_requests":
+			evt.MembershipRequestMethod = cag.OptionalString("request_method")
+			evt.MembershipRequestsCreated, lidPairs = parseParticipantListWithTag(&child, "requested_user")
+		case "revoked_membership_requests":
+			evt.MembershipRequestsRevoked, lidPairs = parseParticipantList(&child)
 		default:
 			evt.UnknownChanges = append(evt.UnknownChanges, &child)
 		}
@@ -1030,3 +1139,30 @@ func (cli *Client) SetGroupDescription(jid types.JID, description string) error
 	_, err := cli.sendGroupIQ(context.TODO(), iqSet, jid, content)
 	return err
 }
+
+// ResetGroupSenderKey discards our own outgoing sender key for the given group, so that a fresh key is generated
+// and distributed to all participants the next time a message is sent to the group.
+//
+// This is useful if the key may have been compromised, or if some participants persistently fail to decrypt
+// messages from us. Messages that were already sent can still be decrypted by the recipients.
+func (cli *Client) ResetGroupSenderKey(ctx context.Context, jid types.JID) error {
+	if jid.Server != types.GroupServer && jid.Server != types.BroadcastServer {
+		return fmt.Errorf("%s is not a group or broadcast list", jid)
+	}
+	ownID := cli.getOwnID()
+	if ownID.IsEmpty() {
+		return ErrNotLoggedIn
+	}
+	cli.messageSendLock.Lock()
+	defer cli.messageSendLock.Unlock()
+	for _, sender := range []types.JID{cli.getOwnLID(), ownID} {
+		if sender.IsEmpty() {
+			continue
+		}
+		err := cli.Store.SenderKeys.DeleteSenderKey(ctx, jid.String(), sender.SignalAddress().String())
+		if err != nil {
+			return fmt.Errorf("failed to delete sender key of %s in %s: %w", sender, jid, err)
+		}
+	}
+	return nil
+}
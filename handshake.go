@@ -8,6 +8,7 @@ package whatsmeow
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"time"
 
@@ -17,6 +18,7 @@ import (
 	"go.mau.fi/whatsmeow/proto/waCert"
 	"go.mau.fi/whatsmeow/proto/waWa6"
 	"go.mau.fi/whatsmeow/socket"
+	"go.mau.fi/whatsmeow/store"
 	"go.mau.fi/whatsmeow/util/keys"
 )
 
@@ -26,7 +28,7 @@ const WACertIssuerSerial = 0
 var WACertPubKey = [...]byte{0x14, 0x23, 0x75, 0x57, 0x4d, 0xa, 0x58, 0x71, 0x66, 0xaa, 0xe7, 0x1e, 0xbe, 0x51, 0x64, 0x37, 0xc4, 0xa2, 0x8b, 0x73, 0xe3, 0x69, 0x5c, 0x6c, 0xe1, 0xf7, 0xf9, 0x54, 0x5d, 0xa8, 0xee, 0x6b}
 
 // doHandshake implements the Noise_XX_25519_AESGCM_SHA256 handshake for the WhatsApp web API.
-func (cli *Client) doHandshake(fs *socket.FrameSocket, ephemeralKP keys.KeyPair) error {
+func (cli *Client) doHandshake(ctx context.Context, fs *socket.FrameSocket, ephemeralKP keys.KeyPair) error {
 	nh := socket.NewNoiseHandshake()
 	nh.Start(socket.NoiseStartPattern, fs.Header)
 	nh.Authenticate(ephemeralKP.Pub[:])
@@ -85,8 +87,21 @@ func (cli *Client) doHandshake(fs *socket.FrameSocket, ephemeralKP keys.KeyPair)
 		return fmt.Errorf("failed to verify server cert: %w", err)
 	}
 
-	encryptedPubkey := nh.Encrypt(cli.Store.NoiseKey.Pub[:])
-	err = nh.MixSharedSecretIntoKey(*cli.Store.NoiseKey.Priv, serverEphemeralArr)
+	noisePub := cli.Store.GetNoisePublicKey()
+	if noisePub == nil {
+		return store.ErrNoNoiseKey
+	}
+	encryptedPubkey := nh.Encrypt(noisePub[:])
+	if cli.Store.ExternalNoiseKey != nil {
+		var sharedSecret []byte
+		sharedSecret, err = cli.Store.ExternalNoiseKey.X25519(ctx, serverEphemeralArr)
+		if err != nil {
+			return fmt.Errorf("failed to compute shared secret with external noise key: %w", err)
+		}
+		err = nh.MixIntoKey(sharedSecret)
+	} else {
+		err = nh.MixSharedSecretIntoKey(*cli.Store.NoiseKey.Priv, serverEphemeralArr)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to mix noise private key in: %w", err)
 	}
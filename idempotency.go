@@ -0,0 +1,60 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"go.mau.fi/whatsmeow/types"
+)
+
+const idempotencyLockStripes = 16
+
+// lockIdempotencyKey locks the given idempotency key, so that concurrent sends with the same key
+// don't both see the key as unsent and send the message twice.
+func (cli *Client) lockIdempotencyKey(key string) func() {
+	hasher := fnv.New32a()
+	_, _ = hasher.Write([]byte(key))
+	lock := &cli.idempotencyLocks[hasher.Sum32()%idempotencyLockStripes]
+	lock.Lock()
+	return lock.Unlock
+}
+
+func (cli *Client) checkIdempotencyKey(ctx context.Context, to types.JID, req *SendRequestExtra, resp *SendResponse) (alreadySent bool, err error) {
+	to = to.ToNonAD()
+	existing, err := cli.Store.Idempotency.GetIdempotencyKey(ctx, req.IdempotencyKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to check idempotency key: %w", err)
+	} else if existing == nil {
+		err = cli.Store.Idempotency.PutIdempotencyKey(ctx, req.IdempotencyKey, to, req.ID)
+		if err != nil {
+			return false, fmt.Errorf("failed to store idempotency key: %w", err)
+		}
+		return false, nil
+	} else if !existing.Chat.IsEmpty() && existing.Chat != to {
+		return false, fmt.Errorf("%w (key %s was used for %s)", ErrIdempotencyKeyReused, req.IdempotencyKey, existing.Chat)
+	}
+	req.ID = existing.MessageID
+	resp.ID = existing.MessageID
+	if !existing.SentAt.IsZero() {
+		cli.Log.Debugf("Not sending message with idempotency key %s again, it was already sent as %s", req.IdempotencyKey, existing.MessageID)
+		resp.Timestamp = existing.SentAt
+		return true, nil
+	}
+	cli.Log.Debugf("Retrying interrupted send of %s with idempotency key %s", existing.MessageID, req.IdempotencyKey)
+	return false, nil
+}
+
+func (cli *Client) markIdempotencyKeySent(ctx context.Context, key string, ts time.Time) {
+	err := cli.Store.Idempotency.MarkIdempotencyKeySent(context.WithoutCancel(ctx), key, ts)
+	if err != nil {
+		cli.Log.Errorf("Failed to mark idempotency key %s as sent: %v", key, err)
+	}
+}
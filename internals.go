@@ -10,6 +10,8 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"net/url"
+	"sync"
 	"time"
 
 	"go.mau.fi/libsignal/keys/prekey"
@@ -47,8 +49,12 @@ func (int *DangerousInternalClient) FilterContacts(mutations []appstate.Mutation
 	return int.c.filterContacts(mutations)
 }
 
-func (int *DangerousInternalClient) DispatchAppState(ctx context.Context, mutation appstate.Mutation, fullSync bool, emitOnFullSync bool) {
-	int.c.dispatchAppState(ctx, mutation, fullSync, emitOnFullSync)
+func (int *DangerousInternalClient) ApplyAppStateMutations(ctx context.Context, mutations []appstate.Mutation, fullSync bool, emitOnFullSync bool) []any {
+	return int.c.applyAppStateMutations(ctx, mutations, fullSync, emitOnFullSync)
+}
+
+func (int *DangerousInternalClient) ApplyAppStateMutation(ctx context.Context, mutation appstate.Mutation, fullSync bool, emitOnFullSync bool) (evts []any, storeUpdateError error) {
+	return int.c.applyAppStateMutation(ctx, mutation, fullSync, emitOnFullSync)
 }
 
 func (int *DangerousInternalClient) DownloadExternalAppStateBlob(ctx context.Context, ref *waServerSync.ExternalBlobReference) ([]byte, error) {
@@ -67,16 +73,24 @@ func (int *DangerousInternalClient) RequestAppStateKeys(ctx context.Context, raw
 	int.c.requestAppStateKeys(ctx, rawKeyIDs)
 }
 
+func (int *DangerousInternalClient) SendAppStateKeyRequest(ctx context.Context, rawKeyIDs [][]byte) error {
+	return int.c.sendAppStateKeyRequest(ctx, rawKeyIDs)
+}
+
+func (int *DangerousInternalClient) SendAppStatePatch(ctx context.Context, patch appstate.PatchInfo) error {
+	return int.c.sendAppStatePatch(ctx, patch)
+}
+
 func (int *DangerousInternalClient) HandleDecryptedArmadillo(ctx context.Context, info *types.MessageInfo, decrypted []byte, retryCount int) (handled, handlerFailed bool) {
 	return int.c.handleDecryptedArmadillo(ctx, info, decrypted, retryCount)
 }
 
-func (int *DangerousInternalClient) GetBroadcastListParticipants(ctx context.Context, jid types.JID) ([]types.JID, error) {
-	return int.c.getBroadcastListParticipants(ctx, jid)
+func (int *DangerousInternalClient) GetBroadcastListParticipants(ctx context.Context, jid types.JID, statusPrivacy *types.StatusPrivacy) ([]types.JID, error) {
+	return int.c.getBroadcastListParticipants(ctx, jid, statusPrivacy)
 }
 
-func (int *DangerousInternalClient) GetStatusBroadcastRecipients(ctx context.Context) ([]types.JID, error) {
-	return int.c.getStatusBroadcastRecipients(ctx)
+func (int *DangerousInternalClient) GetStatusBroadcastRecipients(ctx context.Context, statusPrivacy *types.StatusPrivacy) ([]types.JID, error) {
+	return int.c.getStatusBroadcastRecipients(ctx, statusPrivacy)
 }
 
 func (int *DangerousInternalClient) HandleCallEvent(node *waBinary.Node) {
@@ -99,12 +113,12 @@ func (int *DangerousInternalClient) GetOwnLID() types.JID {
 	return int.c.getOwnLID()
 }
 
-func (int *DangerousInternalClient) Connect() error {
-	return int.c.connect()
+func (int *DangerousInternalClient) Connect(ctx context.Context) error {
+	return int.c.connect(ctx)
 }
 
-func (int *DangerousInternalClient) UnlockedConnect() error {
-	return int.c.unlockedConnect()
+func (int *DangerousInternalClient) UnlockedConnect(ctx context.Context) error {
+	return int.c.unlockedConnect(ctx)
 }
 
 func (int *DangerousInternalClient) OnDisconnect(ns *socket.NoiseSocket, remote bool) {
@@ -131,10 +145,34 @@ func (int *DangerousInternalClient) UnlockedDisconnect() {
 	int.c.unlockedDisconnect()
 }
 
+func (int *DangerousInternalClient) RemoveCompanionDevice(ctx context.Context, device types.JID) error {
+	return int.c.removeCompanionDevice(ctx, device)
+}
+
 func (int *DangerousInternalClient) HandleFrame(data []byte) {
 	int.c.handleFrame(data)
 }
 
+func (int *DangerousInternalClient) HandleNode(node *waBinary.Node) {
+	int.c.handleNode(node)
+}
+
+func (int *DangerousInternalClient) StartMessageWorkers(stop <-chan struct{}, wg *sync.WaitGroup) {
+	int.c.startMessageWorkers(stop, wg)
+}
+
+func (int *DangerousInternalClient) MessageWorkerLoop(stop <-chan struct{}, queue <-chan *waBinary.Node) {
+	int.c.messageWorkerLoop(stop, queue)
+}
+
+func (int *DangerousInternalClient) GetMessageWorker(ctx context.Context, node *waBinary.Node) chan<- *waBinary.Node {
+	return int.c.getMessageWorker(ctx, node)
+}
+
+func (int *DangerousInternalClient) LockSignalSession(jid types.JID) func() {
+	return int.c.lockSignalSession(jid)
+}
+
 func (int *DangerousInternalClient) HandlerQueueLoop(ctx context.Context) {
 	int.c.handlerQueueLoop(ctx)
 }
@@ -151,10 +189,26 @@ func (int *DangerousInternalClient) DispatchEvent(evt any) (handlerFailed bool)
 	return int.c.dispatchEvent(evt)
 }
 
+func (int *DangerousInternalClient) DispatchEventToHandlers(evt any) (handlerFailed bool) {
+	return int.c.dispatchEventToHandlers(evt)
+}
+
+func (int *DangerousInternalClient) CallEventHandler(handler wrappedEventHandler, evt any) (ok bool) {
+	return int.c.callEventHandler(handler, evt)
+}
+
+func (int *DangerousInternalClient) RecoverHandlerPanic(evt any) {
+	int.c.recoverHandlerPanic(evt)
+}
+
 func (int *DangerousInternalClient) HandleStreamError(node *waBinary.Node) {
 	int.c.handleStreamError(node)
 }
 
+func (int *DangerousInternalClient) SetServerReconnectDelay(node *waBinary.Node) time.Duration {
+	return int.c.setServerReconnectDelay(node)
+}
+
 func (int *DangerousInternalClient) HandleIB(node *waBinary.Node) {
 	int.c.handleIB(node)
 }
@@ -171,20 +225,32 @@ func (int *DangerousInternalClient) DownloadAndDecrypt(ctx context.Context, url
 	return int.c.downloadAndDecrypt(ctx, url, mediaKey, appInfo, fileLength, fileEncSHA256, fileSHA256)
 }
 
-func (int *DangerousInternalClient) DownloadPossiblyEncryptedMediaWithRetries(ctx context.Context, url string, checksum []byte) (file, mac []byte, err error) {
-	return int.c.downloadPossiblyEncryptedMediaWithRetries(ctx, url, checksum)
+func (int *DangerousInternalClient) DownloadPossiblyEncryptedMediaWithRetries(ctx context.Context, url string, checksum []byte, maxSize int64) (file, mac []byte, err error) {
+	return int.c.downloadPossiblyEncryptedMediaWithRetries(ctx, url, checksum, maxSize)
 }
 
 func (int *DangerousInternalClient) DoMediaDownloadRequest(ctx context.Context, url string) (*http.Response, error) {
 	return int.c.doMediaDownloadRequest(ctx, url)
 }
 
-func (int *DangerousInternalClient) DownloadMedia(ctx context.Context, url string) ([]byte, error) {
-	return int.c.downloadMedia(ctx, url)
+func (int *DangerousInternalClient) DoMediaRangeRequest(ctx context.Context, url, byteRange string) (*http.Response, error) {
+	return int.c.doMediaRangeRequest(ctx, url, byteRange)
+}
+
+func (int *DangerousInternalClient) DownloadMedia(ctx context.Context, url string, maxSize int64) ([]byte, error) {
+	return int.c.downloadMedia(ctx, url, maxSize)
+}
+
+func (int *DangerousInternalClient) DownloadEncryptedMedia(ctx context.Context, url string, checksum []byte, maxSize int64) (file, mac []byte, err error) {
+	return int.c.downloadEncryptedMedia(ctx, url, checksum, maxSize)
+}
+
+func (int *DangerousInternalClient) DownloadMediaParallel(ctx context.Context, url string, maxSize int64) ([]byte, error) {
+	return int.c.downloadMediaParallel(ctx, url, maxSize)
 }
 
-func (int *DangerousInternalClient) DownloadEncryptedMedia(ctx context.Context, url string, checksum []byte) (file, mac []byte, err error) {
-	return int.c.downloadEncryptedMedia(ctx, url, checksum)
+func (int *DangerousInternalClient) DownloadMediaRange(ctx context.Context, url string, into []byte, start int64) error {
+	return int.c.downloadMediaRange(ctx, url, into, start)
 }
 
 func (int *DangerousInternalClient) DownloadAndDecryptToFile(ctx context.Context, url string, mediaKey []byte, appInfo MediaType, fileLength int, fileEncSHA256, fileSHA256 []byte, file File) error {
@@ -207,6 +273,10 @@ func (int *DangerousInternalClient) SendGroupIQ(ctx context.Context, iqType info
 	return int.c.sendGroupIQ(ctx, iqType, jid, content)
 }
 
+func (int *DangerousInternalClient) UpdateGroupParticipants(ctx context.Context, jid types.JID, participantChanges []types.JID, action ParticipantChange) ([]types.GroupParticipant, error) {
+	return int.c.updateGroupParticipants(ctx, jid, participantChanges, action)
+}
+
 func (int *DangerousInternalClient) GetGroupInfo(ctx context.Context, jid types.JID, lockParticipantCache bool) (*types.GroupInfo, error) {
 	return int.c.getGroupInfo(ctx, jid, lockParticipantCache)
 }
@@ -235,8 +305,8 @@ func (int *DangerousInternalClient) ParseGroupNotification(node *waBinary.Node)
 	return int.c.parseGroupNotification(node)
 }
 
-func (int *DangerousInternalClient) DoHandshake(fs *socket.FrameSocket, ephemeralKP keys.KeyPair) error {
-	return int.c.doHandshake(fs, ephemeralKP)
+func (int *DangerousInternalClient) DoHandshake(ctx context.Context, fs *socket.FrameSocket, ephemeralKP keys.KeyPair) error {
+	return int.c.doHandshake(ctx, fs, ephemeralKP)
 }
 
 func (int *DangerousInternalClient) KeepAliveLoop(ctx context.Context) {
@@ -315,6 +385,22 @@ func (int *DangerousInternalClient) HandleHistorySyncNotificationLoop() {
 	int.c.handleHistorySyncNotificationLoop()
 }
 
+func (int *DangerousInternalClient) QueueHistorySyncChunk(ctx context.Context, notif *waE2E.HistorySyncNotification) {
+	int.c.queueHistorySyncChunk(ctx, notif)
+}
+
+func (int *DangerousInternalClient) FlushHeldHistorySyncChunks(ctx context.Context) {
+	int.c.flushHeldHistorySyncChunks(ctx)
+}
+
+func (int *DangerousInternalClient) HandleHistorySyncChunk(ctx context.Context, notif *waE2E.HistorySyncNotification) {
+	int.c.handleHistorySyncChunk(ctx, notif)
+}
+
+func (int *DangerousInternalClient) DownloadHistorySyncBlob(ctx context.Context, notif *waE2E.HistorySyncNotification) ([]byte, error) {
+	return int.c.downloadHistorySyncBlob(ctx, notif)
+}
+
 func (int *DangerousInternalClient) HandleAppStateSyncKeyShare(ctx context.Context, keys *waE2E.AppStateSyncKeyShare) {
 	int.c.handleAppStateSyncKeyShare(ctx, keys)
 }
@@ -323,6 +409,10 @@ func (int *DangerousInternalClient) HandlePlaceholderResendResponse(msg *waE2E.P
 	return int.c.handlePlaceholderResendResponse(msg)
 }
 
+func (int *DangerousInternalClient) HandlePlaceholderResendRequest(ctx context.Context, info *types.MessageInfo, req *waE2E.PeerDataOperationRequestMessage) {
+	int.c.handlePlaceholderResendRequest(ctx, info, req)
+}
+
 func (int *DangerousInternalClient) HandleProtocolMessage(ctx context.Context, info *types.MessageInfo, msg *waE2E.Message) (ok bool) {
 	return int.c.handleProtocolMessage(ctx, info, msg)
 }
@@ -331,6 +421,10 @@ func (int *DangerousInternalClient) ProcessProtocolParts(ctx context.Context, in
 	return int.c.processProtocolParts(ctx, info, msg)
 }
 
+func (int *DangerousInternalClient) HandleKeepInChatMessage(info *types.MessageInfo, keep *waE2E.KeepInChatMessage) {
+	int.c.handleKeepInChatMessage(info, keep)
+}
+
 func (int *DangerousInternalClient) StoreMessageSecret(ctx context.Context, info *types.MessageInfo, msg *waE2E.Message) {
 	int.c.storeMessageSecret(ctx, info, msg)
 }
@@ -351,6 +445,14 @@ func (int *DangerousInternalClient) StoreHistoricalPNLIDMappings(ctx context.Con
 	int.c.storeHistoricalPNLIDMappings(ctx, mappings)
 }
 
+func (int *DangerousInternalClient) IsDuplicateMessage(info *types.MessageInfo) bool {
+	return int.c.isDuplicateMessage(info)
+}
+
+func (int *DangerousInternalClient) ForgetDuplicateMessage(info *types.MessageInfo) {
+	int.c.forgetDuplicateMessage(info)
+}
+
 func (int *DangerousInternalClient) HandleDecryptedMessage(ctx context.Context, info *types.MessageInfo, msg *waE2E.Message, retryCount int) bool {
 	return int.c.handleDecryptedMessage(ctx, info, msg, retryCount)
 }
@@ -371,6 +473,14 @@ func (int *DangerousInternalClient) DecryptBotMessage(ctx context.Context, messa
 	return int.c.decryptBotMessage(ctx, messageSecret, msMsg, messageID, targetSenderJID, info)
 }
 
+func (int *DangerousInternalClient) HandleEventResponse(ctx context.Context, info *types.MessageInfo, msg *waE2E.Message) {
+	int.c.handleEventResponse(ctx, info, msg)
+}
+
+func (int *DangerousInternalClient) KeepNewsletterSubscription(ctx context.Context, jid types.JID, dur time.Duration) {
+	int.c.keepNewsletterSubscription(ctx, jid, dur)
+}
+
 func (int *DangerousInternalClient) SendMexIQ(ctx context.Context, queryID string, variables any) (json.RawMessage, error) {
 	return int.c.sendMexIQ(ctx, queryID, variables)
 }
@@ -411,6 +521,10 @@ func (int *DangerousInternalClient) HandleAccountSyncNotification(ctx context.Co
 	int.c.handleAccountSyncNotification(ctx, node)
 }
 
+func (int *DangerousInternalClient) HandleOwnStatusSync(ts time.Time, node *waBinary.Node) {
+	int.c.handleOwnStatusSync(ts, node)
+}
+
 func (int *DangerousInternalClient) HandlePrivacyTokenNotification(ctx context.Context, node *waBinary.Node) {
 	int.c.handlePrivacyTokenNotification(ctx, node)
 }
@@ -451,8 +565,8 @@ func (int *DangerousInternalClient) HandlePairDevice(node *waBinary.Node) {
 	int.c.handlePairDevice(node)
 }
 
-func (int *DangerousInternalClient) MakeQRData(ref string) string {
-	return int.c.makeQRData(ref)
+func (int *DangerousInternalClient) MakeQRData(ref string, noisePub *[32]byte) string {
+	return int.c.makeQRData(ref, noisePub)
 }
 
 func (int *DangerousInternalClient) HandlePairSuccess(node *waBinary.Node) {
@@ -487,6 +601,14 @@ func (int *DangerousInternalClient) HandlePresence(node *waBinary.Node) {
 	int.c.handlePresence(node)
 }
 
+func (int *DangerousInternalClient) UpdateDefaultDisappearingTimer(ctx context.Context, timer time.Duration) {
+	int.c.updateDefaultDisappearingTimer(ctx, timer)
+}
+
+func (int *DangerousInternalClient) HandleDisappearingModeNotification(ctx context.Context, node *waBinary.Node) {
+	int.c.handleDisappearingModeNotification(ctx, node)
+}
+
 func (int *DangerousInternalClient) ParsePrivacySettings(privacyNode *waBinary.Node, settings *types.PrivacySettings) *events.PrivacySettings {
 	return int.c.parsePrivacySettings(privacyNode, settings)
 }
@@ -527,6 +649,14 @@ func (int *DangerousInternalClient) ClearResponseWaiters(node *waBinary.Node) {
 	int.c.clearResponseWaiters(node)
 }
 
+func (int *DangerousInternalClient) AddResponseCleanup(reqID string, cleanup func()) {
+	int.c.addResponseCleanup(reqID, cleanup)
+}
+
+func (int *DangerousInternalClient) PopResponseCleanup(reqID string) func() {
+	return int.c.popResponseCleanup(reqID)
+}
+
 func (int *DangerousInternalClient) WaitResponse(reqID string) chan *waBinary.Node {
 	return int.c.waitResponse(reqID)
 }
@@ -535,6 +665,10 @@ func (int *DangerousInternalClient) CancelResponse(reqID string, ch chan *waBina
 	int.c.cancelResponse(reqID, ch)
 }
 
+func (int *DangerousInternalClient) ExpireResponse(reqID string) {
+	int.c.expireResponse(reqID)
+}
+
 func (int *DangerousInternalClient) ReceiveResponse(data *waBinary.Node) bool {
 	return int.c.receiveResponse(data)
 }
@@ -547,6 +681,10 @@ func (int *DangerousInternalClient) SendIQAsync(query infoQuery) (<-chan *waBina
 	return int.c.sendIQAsync(query)
 }
 
+func (int *DangerousInternalClient) ShouldResendIQ(query *infoQuery) bool {
+	return int.c.shouldResendIQ(query)
+}
+
 func (int *DangerousInternalClient) SendIQ(query infoQuery) (*waBinary.Node, error) {
 	return int.c.sendIQ(query)
 }
@@ -667,10 +805,18 @@ func (int *DangerousInternalClient) RawUpload(ctx context.Context, dataToUpload
 	return int.c.rawUpload(ctx, dataToUpload, uploadSize, fileHash, appInfo, newsletter, resp)
 }
 
+func (int *DangerousInternalClient) UploadToHost(ctx context.Context, host, uploadPrefix, mmsType, token string, q url.Values, dataToUpload io.Reader, uploadSize uint64, resp *UploadResponse) error {
+	return int.c.uploadToHost(ctx, host, uploadPrefix, mmsType, token, q, dataToUpload, uploadSize, resp)
+}
+
 func (int *DangerousInternalClient) ParseBusinessProfile(node *waBinary.Node) (*types.BusinessProfile, error) {
 	return int.c.parseBusinessProfile(node)
 }
 
+func (int *DangerousInternalClient) UpdateUserAbout(jid types.JID, about types.UserAbout, alwaysDispatch bool) {
+	int.c.updateUserAbout(jid, about, alwaysDispatch)
+}
+
 func (int *DangerousInternalClient) HandleHistoricalPushNames(ctx context.Context, names []*waHistorySync.Pushname) {
 	int.c.handleHistoricalPushNames(ctx, names)
 }
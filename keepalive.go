@@ -83,3 +83,24 @@ func (cli *Client) sendKeepAlive(ctx context.Context) (isSuccess, shouldContinue
 		return false, false
 	}
 }
+
+// Ping sends a ping to the WhatsApp server (the same request that is used for keepalives)
+// and returns the round-trip time.
+func (cli *Client) Ping(ctx context.Context) (time.Duration, error) {
+	if cli == nil {
+		return 0, ErrClientIsNil
+	}
+	start := time.Now()
+	_, err := cli.sendIQ(infoQuery{
+		Context:   ctx,
+		Namespace: "w:p",
+		Type:      "get",
+		To:        types.ServerJID,
+		Timeout:   KeepAliveResponseDeadline,
+		NoRetry:   true,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return time.Since(start), nil
+}
@@ -41,13 +41,36 @@ func (mc *MediaConn) Expiry() time.Time {
 	return mc.FetchedAt.Add(time.Duration(mc.TTL) * time.Second)
 }
 
+// AuthExpiry returns the time when the upload auth token in the MediaConn expires.
+// If the server didn't specify a separate auth TTL, this is the same as Expiry.
+func (mc *MediaConn) AuthExpiry() time.Time {
+	if mc.AuthTTL <= 0 {
+		return mc.Expiry()
+	}
+	return mc.FetchedAt.Add(time.Duration(mc.AuthTTL) * time.Second)
+}
+
+// IsExpired returns true if either the host list or the auth token of the MediaConn has expired.
+func (mc *MediaConn) IsExpired() bool {
+	now := time.Now()
+	return now.After(mc.Expiry()) || now.After(mc.AuthExpiry())
+}
+
+// RefreshMediaConn returns the current media connection info (hosts and upload auth token),
+// fetching new info from the server if the cached info has expired or if force is true.
+//
+// Uploads and downloads call this automatically, so it's only needed for making custom media requests.
+func (cli *Client) RefreshMediaConn(ctx context.Context, force bool) (*MediaConn, error) {
+	return cli.refreshMediaConn(ctx, force)
+}
+
 func (cli *Client) refreshMediaConn(ctx context.Context, force bool) (*MediaConn, error) {
 	if cli == nil {
 		return nil, ErrClientIsNil
 	}
 	cli.mediaConnLock.Lock()
 	defer cli.mediaConnLock.Unlock()
-	if cli.mediaConnCache == nil || force || time.Now().After(cli.mediaConnCache.Expiry()) {
+	if cli.mediaConnCache == nil || force || cli.mediaConnCache.IsExpired() {
 		var err error
 		cli.mediaConnCache, err = cli.queryMediaConn(ctx)
 		if err != nil {
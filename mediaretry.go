@@ -8,6 +8,7 @@ package whatsmeow
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"go.mau.fi/util/random"
@@ -181,5 +182,78 @@ func (cli *Client) handleMediaRetryNotification(ctx context.Context, node *waBin
 		cli.Log.Warnf("Failed to parse media retry notification: %v", err)
 		return
 	}
+	cli.mediaRetryWaitersLock.Lock()
+	waiter, ok := cli.mediaRetryWaiters[evt.MessageID]
+	if ok {
+		delete(cli.mediaRetryWaiters, evt.MessageID)
+	}
+	cli.mediaRetryWaitersLock.Unlock()
+	if ok {
+		waiter <- evt
+	}
 	cli.dispatchEvent(evt)
 }
+
+// RequestMediaReupload asks the phone to re-upload the given media to the server and waits for the response.
+// If the re-upload is successful, the new direct path for the media is returned.
+//
+// This is a blocking wrapper for SendMediaRetryReceipt and DecryptMediaRetryNotification.
+// An *events.MediaRetry event is still dispatched for the response. If the context doesn't have a deadline,
+// the request will time out after 75 seconds.
+func (cli *Client) RequestMediaReupload(ctx context.Context, message *types.MessageInfo, media DownloadableMessage) (string, error) {
+	if cli == nil {
+		return "", ErrClientIsNil
+	}
+	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, defaultRequestTimeout)
+		defer cancel()
+	}
+	waiter := make(chan *events.MediaRetry, 1)
+	cli.mediaRetryWaitersLock.Lock()
+	cli.mediaRetryWaiters[message.ID] = waiter
+	cli.mediaRetryWaitersLock.Unlock()
+	defer func() {
+		cli.mediaRetryWaitersLock.Lock()
+		if cli.mediaRetryWaiters[message.ID] == waiter {
+			delete(cli.mediaRetryWaiters, message.ID)
+		}
+		cli.mediaRetryWaitersLock.Unlock()
+	}()
+	err := cli.SendMediaRetryReceipt(message, media.GetMediaKey())
+	if err != nil {
+		return "", fmt.Errorf("failed to send media retry receipt: %w", err)
+	}
+	var evt *events.MediaRetry
+	select {
+	case evt = <-waiter:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+	retryData, err := DecryptMediaRetryNotification(evt, media.GetMediaKey())
+	if err != nil {
+		return "", err
+	} else if retryData.GetResult() != waMmsRetry.MediaRetryNotification_SUCCESS {
+		return "", fmt.Errorf("%w: %s", ErrMediaReuploadFailed, retryData.GetResult())
+	} else if retryData.GetDirectPath() == "" {
+		return "", fmt.Errorf("%w: no direct path in response", ErrMediaReuploadFailed)
+	}
+	return retryData.GetDirectPath(), nil
+}
+
+// DownloadWithReupload downloads the given media like Download, but if the media has expired from the server
+// (i.e. the download fails with HTTP 404 or 410), it asks the phone to re-upload it using RequestMediaReupload
+// and then downloads it from the new path.
+func (cli *Client) DownloadWithReupload(ctx context.Context, message *types.MessageInfo, msg DownloadableMessage) ([]byte, error) {
+	data, err := cli.Download(ctx, msg)
+	if !errors.Is(err, ErrMediaDownloadFailedWith404) && !errors.Is(err, ErrMediaDownloadFailedWith410) {
+		return data, err
+	}
+	cli.Log.Debugf("Media in %s expired (%v), requesting re-upload", message.ID, err)
+	directPath, err := cli.RequestMediaReupload(ctx, message, msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request media re-upload: %w", err)
+	}
+	mediaType := GetMediaType(msg)
+	return cli.DownloadMediaWithPath(ctx, directPath, msg.GetFileEncSHA256(), msg.GetFileSHA256(), msg.GetMediaKey(), getSize(msg), mediaType, mediaTypeToMMSType[mediaType])
+}
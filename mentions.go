@@ -0,0 +1,102 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"context"
+	"regexp"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+)
+
+var mentionRegex = regexp.MustCompile(`@(\d{5,})`)
+
+// ParseMentions finds all @<number> tokens in the given text and returns the corresponding JIDs,
+// which can be put in the MentionedJID field of the message's ContextInfo. Each JID is only included once.
+//
+// The server parameter should be types.DefaultUserServer for phone numbers, or types.HiddenUserServer
+// when the numbers in the text are LIDs (e.g. in LID-addressed groups).
+//
+//	text := "Hello @1234567890"
+//	msg := &waE2E.Message{ExtendedTextMessage: &waE2E.ExtendedTextMessage{
+//		Text:        &text,
+//		ContextInfo: &waE2E.ContextInfo{MentionedJID: whatsmeow.ParseMentions(text, types.DefaultUserServer)},
+//	}}
+func ParseMentions(text, server string) []string {
+	matches := mentionRegex.FindAllStringSubmatch(text, -1)
+	if len(matches) == 0 {
+		return nil
+	}
+	mentions := make([]string, 0, len(matches))
+	seen := make(map[string]struct{}, len(matches))
+	for _, match := range matches {
+		if _, alreadySeen := seen[match[1]]; alreadySeen {
+			continue
+		}
+		seen[match[1]] = struct{}{}
+		mentions = append(mentions, types.NewJID(match[1], server).String())
+	}
+	return mentions
+}
+
+// ExpandMentions replaces the @<number> tokens of the mentioned users in the given text with their names
+// from the contact store. Mentions of users whose names aren't known are left as-is.
+//
+// This is meant for incoming messages, e.g.
+//
+//	text := cli.ExpandMentions(ctx, msg.GetExtendedTextMessage().GetText(), msg.GetExtendedTextMessage().GetContextInfo())
+func (cli *Client) ExpandMentions(ctx context.Context, text string, contextInfo *waE2E.ContextInfo) string {
+	if len(contextInfo.GetMentionedJID()) == 0 {
+		return text
+	}
+	names := make(map[string]string, len(contextInfo.GetMentionedJID()))
+	for _, jidStr := range contextInfo.GetMentionedJID() {
+		jid, err := types.ParseJID(jidStr)
+		if err != nil {
+			cli.Log.Debugf("Failed to parse mentioned JID %s: %v", jidStr, err)
+			continue
+		}
+		name := cli.getMentionName(ctx, jid)
+		if name != "" {
+			names[jid.User] = name
+		}
+	}
+	return mentionRegex.ReplaceAllStringFunc(text, func(token string) string {
+		name, ok := names[token[1:]]
+		if !ok {
+			return token
+		}
+		return "@" + name
+	})
+}
+
+func (cli *Client) getMentionName(ctx context.Context, jid types.JID) string {
+	contact, err := cli.Store.Contacts.GetContact(ctx, jid)
+	if err != nil {
+		cli.Log.Warnf("Failed to get contact info of mentioned user %s: %v", jid, err)
+		return ""
+	} else if !contact.Found && jid.Server == types.HiddenUserServer {
+		pn, err := cli.Store.LIDs.GetPNForLID(ctx, jid)
+		if err != nil {
+			cli.Log.Warnf("Failed to get phone number of mentioned user %s: %v", jid, err)
+			return ""
+		} else if !pn.IsEmpty() {
+			return cli.getMentionName(ctx, pn)
+		}
+	}
+	switch {
+	case contact.FullName != "":
+		return contact.FullName
+	case contact.FirstName != "":
+		return contact.FirstName
+	case contact.BusinessName != "":
+		return contact.BusinessName
+	default:
+		return contact.PushName
+	}
+}
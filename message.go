@@ -15,7 +15,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"runtime/debug"
+	"slices"
 	"strconv"
 	"time"
 
@@ -295,6 +297,15 @@ func (cli *Client) decryptMessages(ctx context.Context, info *types.MessageInfo,
 		} else {
 			cli.Log.Warnf("No LID found for %s", info.Sender)
 		}
+	} else if info.Sender.Server == types.HiddenUserServer && info.SenderAlt.IsEmpty() {
+		// Fill the phone number from the mapping store if the server didn't include it,
+		// so that applications can resolve the sender consistently.
+		if pn, err := cli.Store.LIDs.GetPNForLID(ctx, info.Sender); err != nil {
+			cli.Log.Errorf("Failed to get phone number for %s: %v", info.Sender, err)
+		} else if !pn.IsEmpty() {
+			info.SenderAlt = pn
+			info.SenderAlt.Device = info.Sender.Device
+		}
 	}
 	for _, child := range children {
 		if child.Tag != "enc" {
@@ -492,6 +503,7 @@ func (cli *Client) decryptDM(ctx context.Context, child *waBinary.Node, from typ
 		return nil, nil, fmt.Errorf("message content is not a byte slice")
 	}
 
+	defer cli.lockSignalSession(from)()
 	builder := session.NewBuilderFromSignal(cli.Store, from.SignalAddress(), pbSerializer)
 	cipher := session.NewCipher(builder, from.SignalAddress())
 	var plaintext []byte
@@ -624,23 +636,111 @@ func (cli *Client) handleHistorySyncNotificationLoop() {
 		}
 	}()
 	ctx := cli.BackgroundEventCtx
-	for notif := range cli.historySyncNotifications {
-		blob, err := cli.DownloadHistorySync(ctx, notif, false)
-		if err != nil {
-			cli.Log.Errorf("Failed to download history sync: %v", err)
-		} else {
-			cli.dispatchEvent(&events.HistorySync{Data: blob})
+	flushTimer := time.NewTimer(HistorySyncChunkWaitTimeout)
+	flushTimer.Stop()
+	defer flushTimer.Stop()
+	// The deadline is armed when chunks start being held and isn't extended by further held chunks,
+	// so a steady stream of out-of-order chunks can't postpone the flush indefinitely.
+	var flushDeadline <-chan time.Time
+	for {
+		select {
+		case notif := <-cli.historySyncNotifications:
+			cli.queueHistorySyncChunk(ctx, notif)
+			if len(cli.historySyncHeldChunks) == 0 {
+				flushTimer.Stop()
+				flushDeadline = nil
+			} else if flushDeadline == nil {
+				flushTimer.Reset(HistorySyncChunkWaitTimeout)
+				flushDeadline = flushTimer.C
+			}
+		case <-flushDeadline:
+			flushDeadline = nil
+			cli.flushHeldHistorySyncChunks(ctx)
+		}
+	}
+}
+
+// HistorySyncChunkWaitTimeout is the maximum time to wait for a missing history sync chunk
+// before dispatching later chunks anyway. The timeout starts when the first chunk is held.
+var HistorySyncChunkWaitTimeout = 30 * time.Second
+
+// queueHistorySyncChunk handles the given notification if it's the next chunk of its sync type,
+// or holds it until the previous chunks arrive.
+func (cli *Client) queueHistorySyncChunk(ctx context.Context, notif *waE2E.HistorySyncNotification) {
+	syncType := notif.GetSyncType()
+	chunkOrder := notif.GetChunkOrder()
+	if chunkOrder == 1 {
+		// The first chunk starts a new sync, so the order of previous syncs of the same type (e.g. from
+		// an earlier connection or an on-demand request) doesn't matter anymore.
+		delete(cli.historySyncNextChunk, syncType)
+	}
+	expected, ok := cli.historySyncNextChunk[syncType]
+	if !ok {
+		expected = 1
+	}
+	if chunkOrder > expected {
+		cli.Log.Debugf("Holding history sync chunk %d of type %s until chunk %d arrives", chunkOrder, syncType, expected)
+		held, ok := cli.historySyncHeldChunks[syncType]
+		if !ok {
+			held = make(map[uint32]*waE2E.HistorySyncNotification)
+			cli.historySyncHeldChunks[syncType] = held
+		}
+		held[chunkOrder] = notif
+		return
+	}
+	cli.handleHistorySyncChunk(ctx, notif)
+	held := cli.historySyncHeldChunks[syncType]
+	for next := cli.historySyncNextChunk[syncType]; held[next] != nil; next = cli.historySyncNextChunk[syncType] {
+		notif = held[next]
+		delete(held, next)
+		cli.handleHistorySyncChunk(ctx, notif)
+	}
+	if len(held) == 0 {
+		delete(cli.historySyncHeldChunks, syncType)
+	}
+}
+
+// flushHeldHistorySyncChunks handles all held chunks in order without waiting for the missing ones.
+func (cli *Client) flushHeldHistorySyncChunks(ctx context.Context) {
+	for syncType, held := range cli.historySyncHeldChunks {
+		if len(held) == 0 {
+			continue
+		}
+		cli.Log.Warnf("Timed out waiting for history sync chunk %d of type %s, handling %d later chunks anyway", cli.historySyncNextChunk[syncType], syncType, len(held))
+		for _, chunkOrder := range slices.Sorted(maps.Keys(held)) {
+			cli.handleHistorySyncChunk(ctx, held[chunkOrder])
 		}
+		delete(cli.historySyncHeldChunks, syncType)
+	}
+}
+
+func (cli *Client) handleHistorySyncChunk(ctx context.Context, notif *waE2E.HistorySyncNotification) {
+	if chunkOrder := notif.GetChunkOrder(); chunkOrder > 0 && chunkOrder >= cli.historySyncNextChunk[notif.GetSyncType()] {
+		cli.historySyncNextChunk[notif.GetSyncType()] = chunkOrder + 1
 	}
+	blob, err := cli.DownloadHistorySync(ctx, notif, false)
+	if err != nil {
+		cli.Log.Errorf("Failed to download history sync: %v", err)
+	} else {
+		cli.dispatchEvent(&events.HistorySync{Data: blob})
+	}
+	cli.dispatchEvent(&events.HistorySyncProgress{
+		SyncType:   notif.GetSyncType(),
+		ChunkOrder: notif.GetChunkOrder(),
+		Progress:   notif.GetProgress(),
+		Failed:     err != nil,
+	})
 }
 
 // DownloadHistorySync will download and parse the history sync blob from the given history sync notification.
 //
+// If the notification contains an inline initial bootstrap payload, that is used instead of downloading from the media CDN.
+//
 // You only need to call this manually if you set [Client.ManualHistorySyncDownload] to true.
 // By default, whatsmeow will call this automatically and dispatch an [events.HistorySync] with the parsed data.
 func (cli *Client) DownloadHistorySync(ctx context.Context, notif *waE2E.HistorySyncNotification, synchronousStorage bool) (*waHistorySync.HistorySync, error) {
 	var historySync waHistorySync.HistorySync
-	if data, err := cli.Download(ctx, notif); err != nil {
+	if data, err := cli.downloadHistorySyncBlob(ctx, notif); err != nil {
 		return nil, fmt.Errorf("failed to download: %w", err)
 	} else if reader, err := zlib.NewReader(bytes.NewReader(data)); err != nil {
 		return nil, fmt.Errorf("failed to prepare to decompress: %w", err)
@@ -672,10 +772,18 @@ func (cli *Client) DownloadHistorySync(ctx context.Context, notif *waE2E.History
 	}
 }
 
+func (cli *Client) downloadHistorySyncBlob(ctx context.Context, notif *waE2E.HistorySyncNotification) ([]byte, error) {
+	if inline := notif.GetInitialHistBootstrapInlinePayload(); len(inline) > 0 {
+		return inline, nil
+	}
+	return cli.Download(ctx, notif)
+}
+
 func (cli *Client) handleAppStateSyncKeyShare(ctx context.Context, keys *waE2E.AppStateSyncKeyShare) {
 	onlyResyncIfNotSynced := true
 
 	cli.Log.Debugf("Got %d new app state keys", len(keys.GetKeys()))
+	receivedKeyIDs := make([][]byte, 0, len(keys.GetKeys()))
 	cli.appStateKeyRequestsLock.RLock()
 	for _, key := range keys.GetKeys() {
 		marshaledFingerprint, err := proto.Marshal(key.GetKeyData().GetFingerprint())
@@ -697,8 +805,12 @@ func (cli *Client) handleAppStateSyncKeyShare(ctx context.Context, keys *waE2E.A
 			continue
 		}
 		cli.Log.Debugf("Received app state sync key %X (ts: %d)", key.GetKeyID().GetKeyID(), key.GetKeyData().GetTimestamp())
+		receivedKeyIDs = append(receivedKeyIDs, key.GetKeyID().GetKeyID())
 	}
 	cli.appStateKeyRequestsLock.RUnlock()
+	if len(receivedKeyIDs) > 0 {
+		cli.dispatchEvent(&events.AppStateSyncKeysReceived{KeyIDs: receivedKeyIDs})
+	}
 
 	for _, name := range appstate.AllPatchNames {
 		err := cli.FetchAppState(ctx, name, false, onlyResyncIfNotSynced)
@@ -729,6 +841,45 @@ func (cli *Client) handlePlaceholderResendResponse(msg *waE2E.PeerDataOperationR
 	return
 }
 
+func (cli *Client) handlePlaceholderResendRequest(ctx context.Context, info *types.MessageInfo, req *waE2E.PeerDataOperationRequestMessage) {
+	if info.Sender.Device == cli.getOwnID().Device || cli.GetMessageForPlaceholderResend == nil {
+		return
+	}
+	keys := req.GetPlaceholderMessageResendRequest()
+	results := make([]*waE2E.PeerDataOperationRequestResponseMessage_PeerDataOperationResult, 0, len(keys))
+	for _, item := range keys {
+		webMsg := cli.GetMessageForPlaceholderResend(ctx, item.GetMessageKey())
+		if webMsg == nil {
+			cli.Log.Debugf("Didn't find message %s for placeholder resend request %s", item.GetMessageKey().GetID(), info.ID)
+			continue
+		}
+		webMsgBytes, err := proto.Marshal(webMsg)
+		if err != nil {
+			cli.Log.Warnf("Failed to marshal message %s for placeholder resend request %s: %v", item.GetMessageKey().GetID(), info.ID, err)
+			continue
+		}
+		results = append(results, &waE2E.PeerDataOperationRequestResponseMessage_PeerDataOperationResult{
+			PlaceholderMessageResendResponse: &waE2E.PeerDataOperationRequestResponseMessage_PeerDataOperationResult_PlaceholderMessageResendResponse{
+				WebMessageInfoBytes: webMsgBytes,
+			},
+		})
+	}
+	cli.Log.Debugf("Responding to placeholder resend request %s from %s with %d/%d messages", info.ID, info.Sender, len(results), len(keys))
+	_, err := cli.SendMessage(ctx, info.Sender, &waE2E.Message{
+		ProtocolMessage: &waE2E.ProtocolMessage{
+			Type: waE2E.ProtocolMessage_PEER_DATA_OPERATION_REQUEST_RESPONSE_MESSAGE.Enum(),
+			PeerDataOperationRequestResponseMessage: &waE2E.PeerDataOperationRequestResponseMessage{
+				PeerDataOperationRequestType: waE2E.PeerDataOperationRequestType_PLACEHOLDER_MESSAGE_RESEND.Enum(),
+				StanzaID:                     proto.String(info.ID),
+				PeerDataOperationResult:      results,
+			},
+		},
+	}, SendRequestExtra{Peer: true})
+	if err != nil {
+		cli.Log.Warnf("Failed to send response to placeholder resend request %s: %v", info.ID, err)
+	}
+}
+
 func (cli *Client) handleProtocolMessage(ctx context.Context, info *types.MessageInfo, msg *waE2E.Message) (ok bool) {
 	ok = true
 	protoMsg := msg.GetProtocolMessage()
@@ -755,6 +906,10 @@ func (cli *Client) handleProtocolMessage(ctx context.Context, info *types.Messag
 		ok = cli.handlePlaceholderResendResponse(protoMsg.GetPeerDataOperationRequestResponseMessage()) && ok
 	}
 
+	if protoMsg.GetPeerDataOperationRequestMessage().GetPeerDataOperationRequestType() == waE2E.PeerDataOperationRequestType_PLACEHOLDER_MESSAGE_RESEND {
+		go cli.handlePlaceholderResendRequest(context.WithoutCancel(ctx), info, protoMsg.GetPeerDataOperationRequestMessage())
+	}
+
 	if protoMsg.GetAppStateSyncKeyShare() != nil {
 		go cli.handleAppStateSyncKeyShare(context.WithoutCancel(ctx), protoMsg.AppStateSyncKeyShare)
 	}
@@ -788,9 +943,37 @@ func (cli *Client) processProtocolParts(ctx context.Context, info *types.Message
 	if msg.GetProtocolMessage() != nil {
 		ok = cli.handleProtocolMessage(ctx, info, msg) && ok
 	}
+	// Unlike protocol messages, these can be inside ephemeral messages in chats with disappearing messages enabled
+	if msg.GetEphemeralMessage().GetMessage() != nil {
+		msg = msg.GetEphemeralMessage().GetMessage()
+	}
+	if msg.GetEncEventResponseMessage() != nil {
+		cli.handleEventResponse(ctx, info, msg)
+	}
+	if keep := msg.GetKeepInChatMessage(); keep != nil {
+		cli.handleKeepInChatMessage(info, keep)
+	}
 	return
 }
 
+func (cli *Client) handleKeepInChatMessage(info *types.MessageInfo, keep *waE2E.KeepInChatMessage) {
+	targetSender, err := getOrigSenderFromKey(&events.Message{Info: *info}, keep.GetKey())
+	if err != nil {
+		cli.Log.Warnf("Failed to get sender of kept message %s in %s: %v", keep.GetKey().GetID(), info.Chat, err)
+	}
+	var ts time.Time
+	if keep.TimestampMS != nil {
+		ts = time.UnixMilli(keep.GetTimestampMS())
+	}
+	cli.dispatchEvent(&events.KeepInChat{
+		Info:         *info,
+		TargetID:     keep.GetKey().GetID(),
+		TargetSender: targetSender,
+		Keep:         keep.GetKeepType() != waE2E.KeepType_UNDO_KEEP_FOR_ALL,
+		Timestamp:    ts,
+	})
+}
+
 func (cli *Client) storeMessageSecret(ctx context.Context, info *types.MessageInfo, msg *waE2E.Message) {
 	if msgSecret := msg.GetMessageContextInfo().GetMessageSecret(); len(msgSecret) > 0 {
 		err := cli.Store.MsgSecrets.PutMessageSecret(ctx, info.Chat, info.Sender, info.ID, msgSecret)
@@ -964,13 +1147,68 @@ func (cli *Client) storeHistoricalPNLIDMappings(ctx context.Context, mappings []
 	}
 }
 
+type dedupMessageKey struct {
+	Chat   types.JID
+	Sender types.JID
+	ID     types.MessageID
+}
+
+// isDuplicateMessage checks if the given message was already received recently and remembers it if not.
+// It always returns false if Client.MessageDedupCacheSize is not set.
+func (cli *Client) isDuplicateMessage(info *types.MessageInfo) bool {
+	if cli.MessageDedupCacheSize <= 0 {
+		return false
+	}
+	key := dedupMessageKey{Chat: info.Chat, Sender: info.Sender.ToNonAD(), ID: info.ID}
+	cli.dedupMessagesLock.Lock()
+	defer cli.dedupMessagesLock.Unlock()
+	if len(cli.dedupMessagesList) != cli.MessageDedupCacheSize {
+		cli.dedupMessagesMap = make(map[dedupMessageKey]struct{}, cli.MessageDedupCacheSize)
+		cli.dedupMessagesList = make([]dedupMessageKey, cli.MessageDedupCacheSize)
+		cli.dedupMessagesPtr = 0
+	}
+	if _, exists := cli.dedupMessagesMap[key]; exists {
+		return true
+	}
+	if cli.dedupMessagesList[cli.dedupMessagesPtr].ID != "" {
+		delete(cli.dedupMessagesMap, cli.dedupMessagesList[cli.dedupMessagesPtr])
+	}
+	cli.dedupMessagesMap[key] = struct{}{}
+	cli.dedupMessagesList[cli.dedupMessagesPtr] = key
+	cli.dedupMessagesPtr = (cli.dedupMessagesPtr + 1) % len(cli.dedupMessagesList)
+	return false
+}
+
+func (cli *Client) forgetDuplicateMessage(info *types.MessageInfo) {
+	if cli.MessageDedupCacheSize <= 0 {
+		return
+	}
+	cli.dedupMessagesLock.Lock()
+	delete(cli.dedupMessagesMap, dedupMessageKey{Chat: info.Chat, Sender: info.Sender.ToNonAD(), ID: info.ID})
+	cli.dedupMessagesLock.Unlock()
+}
+
 func (cli *Client) handleDecryptedMessage(ctx context.Context, info *types.MessageInfo, msg *waE2E.Message, retryCount int) bool {
 	ok := cli.processProtocolParts(ctx, info, msg)
 	if !ok {
 		return false
 	}
-	evt := &events.Message{Info: *info, RawMessage: msg, RetryCount: retryCount}
-	return cli.dispatchEvent(evt.UnwrapRaw())
+	if cli.isDuplicateMessage(info) {
+		cli.Log.Debugf("Ignoring duplicate message %s from %s in %s", info.ID, info.SourceString(), info.Chat)
+		return false
+	}
+	evt := (&events.Message{Info: *info, RawMessage: msg, RetryCount: retryCount}).UnwrapRaw()
+	var handlerFailed bool
+	if revoke := evt.AsRevoke(); revoke != nil && cli.DispatchRevokeEvents {
+		handlerFailed = cli.dispatchEvent(revoke)
+	} else {
+		handlerFailed = cli.dispatchEvent(evt)
+	}
+	if handlerFailed {
+		// The message won't be acked, so let the redelivery through
+		cli.forgetDuplicateMessage(info)
+	}
+	return handlerFailed
 }
 
 func (cli *Client) sendProtocolMessageReceipt(id types.MessageID, msgType types.ReceiptType) {
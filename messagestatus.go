@@ -0,0 +1,165 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"maps"
+	"sync"
+	"time"
+
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// MessageStatus contains the delivery status of a message sent by this client.
+type MessageStatus struct {
+	ID     types.MessageID
+	Chat   types.JID
+	SentAt time.Time
+
+	// The users who have received or read the message, with the timestamp of the receipt.
+	// Receipts from the user's own devices are not included.
+	DeliveredTo map[types.JID]time.Time
+	ReadBy      map[types.JID]time.Time
+	PlayedBy    map[types.JID]time.Time
+}
+
+func (ms *MessageStatus) clone() *MessageStatus {
+	clone := *ms
+	clone.DeliveredTo = maps.Clone(ms.DeliveredTo)
+	clone.ReadBy = maps.Clone(ms.ReadBy)
+	clone.PlayedBy = maps.Clone(ms.PlayedBy)
+	return &clone
+}
+
+// MessageStatusTracker correlates sent messages with incoming receipts to keep track of
+// how many users each message has been delivered to and read by.
+//
+//	tracker := whatsmeow.NewMessageStatusTracker(cli)
+//	tracker.OnChange = func(status *whatsmeow.MessageStatus) {
+//		fmt.Println(status.ID, "read by", len(status.ReadBy), "users")
+//	}
+//	resp, err := cli.SendMessage(ctx, chat, msg)
+//	if err == nil {
+//		tracker.Track(chat, resp)
+//	}
+type MessageStatusTracker struct {
+	// OnChange is called with a copy of the status whenever a receipt for a tracked message is received.
+	OnChange func(status *MessageStatus)
+	// MaxAge is the duration after which messages are no longer tracked. Defaults to 7 days.
+	MaxAge time.Duration
+
+	cli       *Client
+	handlerID uint32
+	lock      sync.Mutex
+	messages  map[types.MessageID]*MessageStatus
+}
+
+// NewMessageStatusTracker creates a new message status tracker and registers it as an event handler in the given client.
+//
+// Call Close to unregister the event handler when the tracker is no longer needed.
+func NewMessageStatusTracker(cli *Client) *MessageStatusTracker {
+	tracker := &MessageStatusTracker{
+		MaxAge:   7 * 24 * time.Hour,
+		cli:      cli,
+		messages: make(map[types.MessageID]*MessageStatus),
+	}
+	tracker.handlerID = cli.AddEventHandler(tracker.handleEvent)
+	return tracker
+}
+
+// Close unregisters the tracker's event handler.
+func (mst *MessageStatusTracker) Close() {
+	mst.cli.RemoveEventHandler(mst.handlerID)
+}
+
+// Track starts tracking the status of a message. This should be called with the response of a successful SendMessage call.
+func (mst *MessageStatusTracker) Track(chat types.JID, resp SendResponse) {
+	mst.lock.Lock()
+	defer mst.lock.Unlock()
+	mst.pruneOld()
+	mst.messages[resp.ID] = &MessageStatus{
+		ID:          resp.ID,
+		Chat:        chat,
+		SentAt:      resp.Timestamp,
+		DeliveredTo: make(map[types.JID]time.Time),
+		ReadBy:      make(map[types.JID]time.Time),
+		PlayedBy:    make(map[types.JID]time.Time),
+	}
+}
+
+// Get returns a copy of the current status of the given message, or nil if the message isn't tracked.
+func (mst *MessageStatusTracker) Get(id types.MessageID) *MessageStatus {
+	mst.lock.Lock()
+	defer mst.lock.Unlock()
+	status, ok := mst.messages[id]
+	if !ok {
+		return nil
+	}
+	return status.clone()
+}
+
+// Forget stops tracking the given message.
+func (mst *MessageStatusTracker) Forget(id types.MessageID) {
+	mst.lock.Lock()
+	delete(mst.messages, id)
+	mst.lock.Unlock()
+}
+
+func (mst *MessageStatusTracker) pruneOld() {
+	if mst.MaxAge <= 0 {
+		return
+	}
+	cutoff := time.Now().Add(-mst.MaxAge)
+	for id, status := range mst.messages {
+		if status.SentAt.Before(cutoff) {
+			delete(mst.messages, id)
+		}
+	}
+}
+
+func (mst *MessageStatusTracker) handleEvent(rawEvt any) {
+	evt, ok := rawEvt.(*events.Receipt)
+	if !ok || evt.IsFromMe {
+		return
+	}
+	user := evt.Sender.ToNonAD()
+	var changed []*MessageStatus
+	mst.lock.Lock()
+	for _, id := range evt.MessageIDs {
+		status, ok := mst.messages[id]
+		if !ok {
+			continue
+		}
+		var target map[types.JID]time.Time
+		switch {
+		case evt.Type.IsDelivery():
+			target = status.DeliveredTo
+		case evt.Type == types.ReceiptTypeRead:
+			target = status.ReadBy
+		case evt.Type == types.ReceiptTypePlayed:
+			target = status.PlayedBy
+		default:
+			continue
+		}
+		if _, alreadySet := target[user]; alreadySet {
+			continue
+		}
+		target[user] = evt.Timestamp
+		// Read and played receipts imply delivery, but the delivery receipt may not be sent separately
+		if _, delivered := status.DeliveredTo[user]; !delivered {
+			status.DeliveredTo[user] = evt.Timestamp
+		}
+		changed = append(changed, status.clone())
+	}
+	mst.lock.Unlock()
+	if mst.OnChange != nil {
+		for _, status := range changed {
+			mst.OnChange(status)
+		}
+	}
+}
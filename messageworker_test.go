@@ -0,0 +1,111 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	waBinary "go.mau.fi/whatsmeow/binary"
+	"go.mau.fi/whatsmeow/types"
+)
+
+func TestGetMessageWorkerLIDAndPN(t *testing.T) {
+	ctx := context.Background()
+	cli := newTestStoreClient(t)
+	cli.ParallelMessageWorkers = 16
+	var workersDone sync.WaitGroup
+	stop := make(chan struct{})
+	cli.startMessageWorkers(stop, &workersDone)
+	close(stop)
+	workersDone.Wait()
+
+	ownDevice := *cli.Store.ID
+	ownDevice.Device = 2
+	for i := range 50 {
+		pn := types.NewJID(strconv.Itoa(1000000+i), types.DefaultUserServer)
+		lid := types.NewJID(strconv.Itoa(2000000+i), types.HiddenUserServer)
+		pnDevice, lidDevice := pn, lid
+		pnDevice.Device, lidDevice.Device = 3, 3
+		if err := cli.Store.LIDs.PutLIDMapping(ctx, lid, pn); err != nil {
+			t.Fatalf("failed to store LID mapping: %v", err)
+		}
+		expected := cli.getMessageWorker(ctx, &waBinary.Node{Tag: "message", Attrs: waBinary.Attrs{"from": pn}})
+		nodes := map[string]waBinary.Attrs{
+			"pn device":              {"from": pnDevice},
+			"lid with sender_pn":     {"from": lidDevice, "sender_pn": pnDevice},
+			"lid with stored number": {"from": lid},
+			"own device to pn":       {"from": ownDevice, "recipient": pn},
+			"own device to lid":      {"from": ownDevice, "recipient": lid, "peer_recipient_pn": pn},
+		}
+		for name, attrs := range nodes {
+			if worker := cli.getMessageWorker(ctx, &waBinary.Node{Tag: "message", Attrs: attrs}); worker != expected {
+				t.Errorf("%s: message in chat %s was assigned to a different worker", name, pn)
+			}
+		}
+	}
+}
+
+func TestMessageWorkerOrderAcrossReconnect(t *testing.T) {
+	const chats = 5
+	const messages = 500
+	cli := newTestClient()
+	cli.ParallelMessageWorkers = 4
+
+	var lock sync.Mutex
+	handled := make(map[string][]int)
+	allHandled := make(chan struct{})
+	var total int
+	cli.nodeHandlers["message"] = func(node *waBinary.Node) {
+		ag := node.AttrGetter()
+		from := ag.JID("from").String()
+		id, _ := strconv.Atoi(ag.String("id"))
+		time.Sleep(10 * time.Microsecond)
+		lock.Lock()
+		handled[from] = append(handled[from], id)
+		total++
+		if total == messages {
+			close(allHandled)
+		}
+		lock.Unlock()
+	}
+	for i := range messages {
+		from := types.NewJID(strconv.Itoa(1000000+i%chats), types.DefaultUserServer)
+		cli.handlerQueue <- &waBinary.Node{Tag: "message", Attrs: waBinary.Attrs{"from": from, "id": strconv.Itoa(i)}}
+	}
+
+	// Simulate a few reconnects while messages are being handled
+	for range 3 {
+		ctx, cancel := context.WithCancel(context.Background())
+		go cli.handlerQueueLoop(ctx)
+		time.Sleep(time.Millisecond)
+		cancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go cli.handlerQueueLoop(ctx)
+
+	select {
+	case <-allHandled:
+	case <-time.After(10 * time.Second):
+		lock.Lock()
+		defer lock.Unlock()
+		t.Fatalf("only %d/%d messages were handled", total, messages)
+	}
+	lock.Lock()
+	defer lock.Unlock()
+	for chat, ids := range handled {
+		for i := 1; i < len(ids); i++ {
+			if ids[i] <= ids[i-1] {
+				t.Fatalf("messages in %s were handled out of order: %d after %d", chat, ids[i], ids[i-1])
+			}
+		}
+	}
+}
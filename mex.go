@@ -0,0 +1,44 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// SendMexQuery sends a MEX (GraphQL over IQ) query and returns the data field of the response.
+//
+// The query ID identifies a persisted GraphQL query in the official clients, and the variables are sent as JSON.
+// If the response contains GraphQL errors, the error will wrap a types.GraphQLErrors value,
+// and the data will still be returned if the server included any.
+func (cli *Client) SendMexQuery(ctx context.Context, queryID string, variables any) (json.RawMessage, error) {
+	if cli == nil {
+		return nil, ErrClientIsNil
+	}
+	return cli.sendMexIQ(ctx, queryID, variables)
+}
+
+// MexQuery sends a MEX query using Client.SendMexQuery and unmarshals the response data into the given type.
+//
+//	type respGetThing struct {
+//		Thing *Thing `json:"xwa2_thing"`
+//	}
+//	resp, err := whatsmeow.MexQuery[respGetThing](ctx, cli, "1234567890", map[string]any{"input": ...})
+func MexQuery[T any](ctx context.Context, cli *Client, queryID string, variables any) (*T, error) {
+	data, err := cli.SendMexQuery(ctx, queryID, variables)
+	if data == nil {
+		return nil, err
+	}
+	var resp T
+	jsonErr := json.Unmarshal(data, &resp)
+	if err == nil && jsonErr != nil {
+		err = fmt.Errorf("failed to unmarshal mex response data: %w", jsonErr)
+	}
+	return &resp, err
+}
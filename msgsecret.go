@@ -360,3 +360,103 @@ func (cli *Client) EncryptReaction(ctx context.Context, rootMsgInfo *types.Messa
 		EncIV:            iv,
 	}, nil
 }
+
+// DecryptEventResponse decrypts an event (calendar invite) RSVP response message.
+//
+// Responses are also decrypted automatically and emitted as *events.EventResponse.
+func (cli *Client) DecryptEventResponse(ctx context.Context, response *events.Message) (*waE2E.EventResponseMessage, error) {
+	encResponse := response.Message.GetEncEventResponseMessage()
+	if encResponse == nil {
+		return nil, ErrNotEncryptedEventResponse
+	}
+	plaintext, err := cli.decryptMsgSecret(ctx, response, EncSecretEventResponse, encResponse, encResponse.GetEventCreationMessageKey())
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt event response: %w", err)
+	}
+	var msg waE2E.EventResponseMessage
+	err = proto.Unmarshal(plaintext, &msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode event response protobuf: %w", err)
+	}
+	return &msg, nil
+}
+
+// BuildEventCreation builds an event (calendar invite) message. Events are only supported in groups.
+// The event should have at least the Name and StartTime (unix seconds) fields set.
+// The built message can be sent normally using Client.SendMessage.
+//
+//	resp, err := cli.SendMessage(context.Background(), groupJID, cli.BuildEventCreation(&waE2E.EventMessage{
+//		Name:      proto.String("Meow meetup"),
+//		StartTime: proto.Int64(startTime.Unix()),
+//	}))
+func (cli *Client) BuildEventCreation(event *waE2E.EventMessage) *waE2E.Message {
+	return &waE2E.Message{
+		EventMessage: event,
+		MessageContextInfo: &waE2E.MessageContextInfo{
+			MessageSecret: random.Bytes(32),
+		},
+	}
+}
+
+// BuildEventResponse builds an RSVP response message to the given event.
+// The built message can be sent normally using Client.SendMessage.
+//
+//	if evt.Message.GetEventMessage() != nil {
+//		msg, err := cli.BuildEventResponse(ctx, &evt.Info, waE2E.EventResponseMessage_GOING, 0)
+//		if err != nil {
+//			fmt.Println(":(", err)
+//			return
+//		}
+//		resp, err := cli.SendMessage(context.Background(), evt.Info.Chat, msg)
+//	}
+func (cli *Client) BuildEventResponse(ctx context.Context, eventInfo *types.MessageInfo, response waE2E.EventResponseMessage_EventResponseType, extraGuests int) (*waE2E.Message, error) {
+	resp := &waE2E.EventResponseMessage{
+		Response:    response.Enum(),
+		TimestampMS: proto.Int64(time.Now().UnixMilli()),
+	}
+	if extraGuests > 0 {
+		resp.ExtraGuestCount = proto.Int32(int32(extraGuests))
+	}
+	encResponse, err := cli.EncryptEventResponse(ctx, eventInfo, resp)
+	return &waE2E.Message{EncEventResponseMessage: encResponse}, err
+}
+
+// EncryptEventResponse encrypts an event response message. This is a slightly lower-level function, using BuildEventResponse is recommended.
+func (cli *Client) EncryptEventResponse(ctx context.Context, eventInfo *types.MessageInfo, response *waE2E.EventResponseMessage) (*waE2E.EncEventResponseMessage, error) {
+	plaintext, err := proto.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal event response protobuf: %w", err)
+	}
+	ciphertext, iv, err := cli.encryptMsgSecret(ctx, cli.getOwnID(), eventInfo.Chat, eventInfo.Sender, eventInfo.ID, EncSecretEventResponse, plaintext)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt event response: %w", err)
+	}
+	return &waE2E.EncEventResponseMessage{
+		EventCreationMessageKey: getKeyFromInfo(eventInfo),
+		EncPayload:              ciphertext,
+		EncIV:                   iv,
+	}, nil
+}
+
+func (cli *Client) handleEventResponse(ctx context.Context, info *types.MessageInfo, msg *waE2E.Message) {
+	evt := &events.Message{Info: *info, Message: msg}
+	response, err := cli.DecryptEventResponse(ctx, evt)
+	if err != nil {
+		cli.Log.Warnf("Failed to decrypt event response %s from %s: %v", info.ID, info.Sender, err)
+		return
+	}
+	eventKey := msg.GetEncEventResponseMessage().GetEventCreationMessageKey()
+	eventSender, _ := getOrigSenderFromKey(evt, eventKey)
+	var ts time.Time
+	if response.TimestampMS != nil {
+		ts = time.UnixMilli(response.GetTimestampMS())
+	}
+	cli.dispatchEvent(&events.EventResponse{
+		Info:            *info,
+		EventID:         eventKey.GetID(),
+		EventSender:     eventSender,
+		Response:        response.GetResponse(),
+		ExtraGuestCount: int(response.GetExtraGuestCount()),
+		Timestamp:       ts,
+	})
+}
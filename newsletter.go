@@ -10,7 +10,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"strings"
 	"time"
 
@@ -42,6 +41,48 @@ func (cli *Client) NewsletterSubscribeLiveUpdates(ctx context.Context, jid types
 	return time.Duration(dur) * time.Second, nil
 }
 
+// SubscribeNewsletterUpdates subscribes to live updates from a WhatsApp channel and keeps the subscription alive
+// by resubscribing shortly before it expires, until the given context is canceled.
+//
+// The updates (view and reaction counts) are emitted as *events.NewsletterLiveUpdate.
+// The initial subscription is done synchronously and its error is returned.
+// Errors from later resubscriptions are only logged and retried.
+func (cli *Client) SubscribeNewsletterUpdates(ctx context.Context, jid types.JID) error {
+	dur, err := cli.NewsletterSubscribeLiveUpdates(ctx, jid)
+	if err != nil {
+		return err
+	}
+	go cli.keepNewsletterSubscription(ctx, jid, dur)
+	return nil
+}
+
+const newsletterResubscribeMargin = 10 * time.Second
+const newsletterResubscribeRetryDelay = 30 * time.Second
+
+func (cli *Client) keepNewsletterSubscription(ctx context.Context, jid types.JID, dur time.Duration) {
+	for {
+		wait := max(dur-newsletterResubscribeMargin, newsletterResubscribeMargin)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+		if !cli.IsConnected() {
+			// Wait for the connection to come back before trying again
+			dur = newsletterResubscribeRetryDelay + newsletterResubscribeMargin
+			continue
+		}
+		var err error
+		dur, err = cli.NewsletterSubscribeLiveUpdates(ctx, jid)
+		if ctx.Err() != nil {
+			return
+		} else if err != nil {
+			cli.Log.Warnf("Failed to resubscribe to live updates of %s: %v", jid, err)
+			dur = newsletterResubscribeRetryDelay + newsletterResubscribeMargin
+		}
+	}
+}
+
 // NewsletterMarkViewed marks a channel message as viewed, incrementing the view counter.
 //
 // This is not the same as marking the channel as read on your other devices, use the usual MarkRead function for that.
@@ -136,6 +177,12 @@ const (
 	mutationCreateNewsletterDesktop    = "27527996220149684"
 	mutationUnfollowNewsletterDesktop  = "8782612271820087"
 	mutationFollowNewsletterDesktop    = "8621797084555037"
+
+	// These IDs are from the argo query map and are used for all platforms
+	mutationNewsletterAdminInvite       = "24943748628557365" // variables -> {newsletter_id, user_id}
+	mutationNewsletterAdminInviteRevoke = "6550386328343169"  // variables -> {newsletter_id, user_id}
+	mutationNewsletterAcceptAdminInvite = "6179636105471882"  // variables -> {newsletter_id}
+	mutationNewsletterAdminDemote       = "7220922401252829"  // variables -> {newsletter_id, user_id}
 )
 
 func convertQueryID(cli *Client, queryID string) string {
@@ -218,7 +265,7 @@ func (cli *Client) sendMexIQ(ctx context.Context, queryID string, variables any)
 		}
 		data, err := decoder.ArgoToMap(wt)
 		if err != nil {
-			log.Fatalf("argo to map error: %v", err)
+			return nil, fmt.Errorf("failed to decode argo response: %w", err)
 		}
 		b, err := json.Marshal(data)
 		if err != nil {
@@ -321,6 +368,70 @@ func (cli *Client) CreateNewsletter(params CreateNewsletterParams) (*types.Newsl
 	return respData.Newsletter, nil
 }
 
+// UpdateNewsletterParams contains the fields to change in UpdateNewsletter. Nil fields are left unchanged.
+type UpdateNewsletterParams struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+	// The new picture as a JPEG image.
+	Picture []byte `json:"picture,omitempty"`
+}
+
+type respUpdateNewsletter struct {
+	Newsletter *types.NewsletterMetadata `json:"xwa2_newsletter_update"`
+}
+
+// UpdateNewsletter changes the name, description and/or picture of a WhatsApp channel you own or administrate.
+func (cli *Client) UpdateNewsletter(ctx context.Context, jid types.JID, params UpdateNewsletterParams) (*types.NewsletterMetadata, error) {
+	resp, err := cli.sendMexIQ(ctx, mutationUpdateNewsletter, map[string]any{
+		"newsletter_id": jid.String(),
+		"updates":       &params,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var respData respUpdateNewsletter
+	err = json.Unmarshal(resp, &respData)
+	if err != nil {
+		return nil, err
+	}
+	return respData.Newsletter, nil
+}
+
+// NewsletterInviteAdmin invites the given user to become an admin of a WhatsApp channel you own.
+func (cli *Client) NewsletterInviteAdmin(ctx context.Context, jid, user types.JID) error {
+	_, err := cli.sendMexIQ(ctx, mutationNewsletterAdminInvite, map[string]any{
+		"newsletter_id": jid.String(),
+		"user_id":       user.ToNonAD().String(),
+	})
+	return err
+}
+
+// NewsletterRevokeAdminInvite revokes a pending admin invite sent with NewsletterInviteAdmin.
+func (cli *Client) NewsletterRevokeAdminInvite(ctx context.Context, jid, user types.JID) error {
+	_, err := cli.sendMexIQ(ctx, mutationNewsletterAdminInviteRevoke, map[string]any{
+		"newsletter_id": jid.String(),
+		"user_id":       user.ToNonAD().String(),
+	})
+	return err
+}
+
+// NewsletterAcceptAdminInvite accepts an invite to become an admin of a WhatsApp channel.
+func (cli *Client) NewsletterAcceptAdminInvite(ctx context.Context, jid types.JID) error {
+	_, err := cli.sendMexIQ(ctx, mutationNewsletterAcceptAdminInvite, map[string]any{
+		"newsletter_id": jid.String(),
+	})
+	return err
+}
+
+// NewsletterDemoteAdmin removes the admin status of the given user in a WhatsApp channel you own.
+func (cli *Client) NewsletterDemoteAdmin(ctx context.Context, jid, user types.JID) error {
+	_, err := cli.sendMexIQ(ctx, mutationNewsletterAdminDemote, map[string]any{
+		"newsletter_id": jid.String(),
+		"user_id":       user.ToNonAD().String(),
+	})
+	return err
+}
+
 // AcceptTOSNotice accepts a ToS notice.
 //
 // To accept the terms for creating newsletters, use
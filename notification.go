@@ -11,6 +11,7 @@ import (
 	"encoding/json"
 	"errors"
 	"slices"
+	"time"
 
 	"google.golang.org/protobuf/proto"
 
@@ -203,7 +204,7 @@ func (cli *Client) handleOwnDevicesNotification(ctx context.Context, node *waBin
 		delete(cli.userDevicesCache, ownID)
 	} else {
 		cli.Log.Debugf("Received own device list change notification %s -> %s", oldHash, newHash)
-		cli.userDevicesCache[ownID] = deviceCache{devices: newDeviceList, dhash: expectedNewHash}
+		cli.userDevicesCache[ownID] = deviceCache{devices: newDeviceList, dhash: expectedNewHash, fetchedAt: time.Now()}
 	}
 }
 
@@ -230,6 +231,7 @@ func (cli *Client) handleBlocklist(ctx context.Context, node *waBinary.Node) {
 }
 
 func (cli *Client) handleAccountSyncNotification(ctx context.Context, node *waBinary.Node) {
+	ts := node.AttrGetter().UnixTime("t")
 	for _, child := range node.GetChildren() {
 		switch child.Tag {
 		case "privacy":
@@ -238,17 +240,34 @@ func (cli *Client) handleAccountSyncNotification(ctx context.Context, node *waBi
 			cli.handleOwnDevicesNotification(ctx, &child)
 		case "picture":
 			cli.dispatchEvent(&events.Picture{
-				Timestamp: node.AttrGetter().UnixTime("t"),
+				Timestamp: ts,
 				JID:       cli.getOwnID().ToNonAD(),
+				PictureID: child.AttrGetter().OptionalString("id"),
 			})
+		case "status":
+			cli.handleOwnStatusSync(ts, &child)
 		case "blocklist":
 			cli.handleBlocklist(ctx, &child)
 		default:
 			cli.Log.Debugf("Unhandled account sync item %s", child.Tag)
+			cli.dispatchEvent(&events.UnknownAccountSync{Timestamp: ts, Node: &child})
 		}
 	}
 }
 
+func (cli *Client) handleOwnStatusSync(ts time.Time, node *waBinary.Node) {
+	status, ok := node.Content.([]byte)
+	if !ok && node.Content != nil {
+		cli.Log.Warnf("Own status sync item has unexpected content (%T)", node.Content)
+		return
+	}
+	cli.dispatchEvent(&events.UserAbout{
+		JID:       cli.getOwnID().ToNonAD(),
+		Timestamp: ts,
+		Status:    string(status),
+	})
+}
+
 func (cli *Client) handlePrivacyTokenNotification(ctx context.Context, node *waBinary.Node) {
 	ownJID := cli.getOwnID().ToNonAD()
 	ownLID := cli.getOwnLID().ToNonAD()
@@ -402,11 +421,7 @@ func (cli *Client) handleStatusNotification(ctx context.Context, node *waBinary.
 		cli.Log.Warnf("Set status notification has unexpected content (%T)", child.Content)
 		return
 	}
-	cli.dispatchEvent(&events.UserAbout{
-		JID:       ag.JID("from"),
-		Timestamp: ag.UnixTime("t"),
-		Status:    string(status),
-	})
+	cli.updateUserAbout(ag.JID("from"), types.UserAbout{Status: string(status), SetAt: ag.UnixTime("t")}, true)
 }
 
 func (cli *Client) handleNotification(node *waBinary.Node) {
@@ -454,7 +469,9 @@ func (cli *Client) handleNotification(node *waBinary.Node) {
 		cli.handleMexNotification(ctx, node)
 	case "status":
 		cli.handleStatusNotification(ctx, node)
-	// Other types: business, disappearing_mode, server, status, pay, psa
+	case "disappearing_mode":
+		cli.handleDisappearingModeNotification(ctx, node)
+	// Other types: business, server, pay, psa
 	default:
 		cli.Log.Debugf("Unhandled notification with type %s", notifType)
 	}
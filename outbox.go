@@ -0,0 +1,118 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/store"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// OutboxMaxAttempts is the number of times sending a message from the outbox can fail
+// (for reasons other than being disconnected) before the message is dropped.
+var OutboxMaxAttempts = 10
+
+// EnqueueMessage persists the given message in the outbox and then sends it in the background.
+//
+// Messages in the outbox are retried after reconnecting (including after restarting the process) until the server
+// acknowledges them, which guarantees at-least-once delivery. The same message ID is used for every attempt.
+// When the message is sent, an *events.OutboxMessageSent is dispatched. If sending fails OutboxMaxAttempts times,
+// the message is dropped and an *events.OutboxMessageFailed is dispatched.
+//
+// Only normal messages are supported, there's no way to pass SendRequestExtra parameters.
+func (cli *Client) EnqueueMessage(ctx context.Context, to types.JID, message *waE2E.Message) (types.MessageID, error) {
+	if cli == nil {
+		return "", ErrClientIsNil
+	}
+	data, err := proto.Marshal(message)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal message: %w", err)
+	}
+	id := cli.GenerateMessageID()
+	err = cli.Store.Outbox.PutOutboxMessage(ctx, &store.OutboxMessage{
+		ID:        id,
+		To:        to,
+		Message:   data,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to store message in outbox: %w", err)
+	}
+	if cli.IsLoggedIn() {
+		go cli.flushOutbox(cli.BackgroundEventCtx)
+	}
+	return id, nil
+}
+
+func isTransientSendError(err error) bool {
+	return errors.Is(err, ErrNotConnected) ||
+		errors.Is(err, ErrIQTimedOut) ||
+		errors.Is(err, ErrMessageTimedOut) ||
+		errors.As(err, new(*DisconnectedError)) ||
+		errors.Is(err, context.Canceled) ||
+		errors.Is(err, context.DeadlineExceeded)
+}
+
+func (cli *Client) flushOutbox(ctx context.Context) {
+	cli.outboxLock.Lock()
+	defer cli.outboxLock.Unlock()
+	msgs, err := cli.Store.Outbox.GetOutboxMessages(ctx)
+	if err != nil {
+		cli.Log.Errorf("Failed to get messages in outbox: %v", err)
+		return
+	} else if len(msgs) > 0 {
+		cli.Log.Debugf("Sending %d messages from outbox", len(msgs))
+	}
+	for _, msg := range msgs {
+		if !cli.sendOutboxMessage(ctx, msg) {
+			return
+		}
+	}
+}
+
+func (cli *Client) sendOutboxMessage(ctx context.Context, msg *store.OutboxMessage) (continueFlush bool) {
+	var parsed waE2E.Message
+	err := proto.Unmarshal(msg.Message, &parsed)
+	if err != nil {
+		cli.Log.Errorf("Failed to unmarshal outbox message %s, dropping it: %v", msg.ID, err)
+		cli.dropOutboxMessage(ctx, msg, err)
+		return true
+	}
+	resp, err := cli.SendMessage(ctx, msg.To, &parsed, SendRequestExtra{ID: msg.ID})
+	if err == nil {
+		if err = cli.Store.Outbox.DeleteOutboxMessage(ctx, msg.ID); err != nil {
+			cli.Log.Errorf("Failed to delete sent message %s from outbox: %v", msg.ID, err)
+		}
+		cli.dispatchEvent(&events.OutboxMessageSent{ID: msg.ID, To: msg.To, Timestamp: resp.Timestamp})
+		return true
+	} else if isTransientSendError(err) {
+		cli.Log.Debugf("Failed to send outbox message %s, will retry after reconnecting: %v", msg.ID, err)
+		return false
+	}
+	cli.Log.Warnf("Failed to send outbox message %s (attempt #%d): %v", msg.ID, msg.Attempts+1, err)
+	if msg.Attempts+1 >= OutboxMaxAttempts {
+		cli.dropOutboxMessage(ctx, msg, err)
+	} else if err = cli.Store.Outbox.IncrementOutboxAttempts(ctx, msg.ID); err != nil {
+		cli.Log.Errorf("Failed to increment attempt count of outbox message %s: %v", msg.ID, err)
+	}
+	return true
+}
+
+func (cli *Client) dropOutboxMessage(ctx context.Context, msg *store.OutboxMessage, sendErr error) {
+	if err := cli.Store.Outbox.DeleteOutboxMessage(ctx, msg.ID); err != nil {
+		cli.Log.Errorf("Failed to delete message %s from outbox: %v", msg.ID, err)
+	}
+	cli.dispatchEvent(&events.OutboxMessageFailed{ID: msg.ID, To: msg.To, Error: sendErr})
+}
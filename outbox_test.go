@@ -0,0 +1,179 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"google.golang.org/protobuf/proto"
+
+	"go.mau.fi/whatsmeow/proto/waAdv"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/store"
+	"go.mau.fi/whatsmeow/store/sqlstore"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// newTestStoreClient creates a logged in client backed by a fresh SQLite database.
+func newTestStoreClient(t *testing.T) *Client {
+	t.Helper()
+	ctx := context.Background()
+	addr := "file:" + filepath.Join(t.TempDir(), "whatsmeow.db") + "?_foreign_keys=on"
+	container, err := sqlstore.New(ctx, "sqlite3", addr, nil)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	t.Cleanup(func() { _ = container.Close() })
+	device := container.NewDevice()
+	ownID := types.NewJID("1234567890", types.DefaultUserServer)
+	ownID.Device = 1
+	device.ID = &ownID
+	device.Account = &waAdv.ADVSignedDeviceIdentity{
+		Details:             []byte{},
+		AccountSignature:    make([]byte, 64),
+		AccountSignatureKey: make([]byte, 32),
+		DeviceSignature:     make([]byte, 64),
+	}
+	if err = device.Save(ctx); err != nil {
+		t.Fatalf("failed to save device: %v", err)
+	}
+	return NewClient(device, nil)
+}
+
+type outboxEventCollector struct {
+	lock   sync.Mutex
+	sent   []*events.OutboxMessageSent
+	failed []*events.OutboxMessageFailed
+}
+
+func (oec *outboxEventCollector) handle(evt any) {
+	oec.lock.Lock()
+	defer oec.lock.Unlock()
+	switch evt := evt.(type) {
+	case *events.OutboxMessageSent:
+		oec.sent = append(oec.sent, evt)
+	case *events.OutboxMessageFailed:
+		oec.failed = append(oec.failed, evt)
+	}
+}
+
+func getOutbox(t *testing.T, cli *Client) []*store.OutboxMessage {
+	t.Helper()
+	msgs, err := cli.Store.Outbox.GetOutboxMessages(context.Background())
+	if err != nil {
+		t.Fatalf("failed to get outbox: %v", err)
+	}
+	return msgs
+}
+
+func TestOutboxTransientFailure(t *testing.T) {
+	ctx := context.Background()
+	cli := newTestStoreClient(t)
+	var collector outboxEventCollector
+	cli.AddEventHandler(collector.handle)
+
+	to := types.NewJID("1111111111", types.DefaultUserServer)
+	msg := &waE2E.Message{Conversation: proto.String("hello")}
+	id, err := cli.EnqueueMessage(ctx, to, msg)
+	if err != nil {
+		t.Fatalf("failed to enqueue message: %v", err)
+	}
+	// The client isn't connected, so every flush should fail with a transient error
+	// and leave the message in the outbox without counting an attempt.
+	for range 3 {
+		cli.flushOutbox(ctx)
+	}
+	msgs := getOutbox(t, cli)
+	if len(msgs) != 1 {
+		t.Fatalf("expected 1 message in outbox, got %d", len(msgs))
+	}
+	if msgs[0].ID != id || msgs[0].To != to || msgs[0].Attempts != 0 {
+		t.Errorf("unexpected outbox message: id=%s to=%s attempts=%d", msgs[0].ID, msgs[0].To, msgs[0].Attempts)
+	}
+	var stored waE2E.Message
+	if err = proto.Unmarshal(msgs[0].Message, &stored); err != nil {
+		t.Fatalf("failed to unmarshal stored message: %v", err)
+	} else if !proto.Equal(&stored, msg) {
+		t.Errorf("stored message doesn't match enqueued message")
+	}
+	if len(collector.sent) != 0 || len(collector.failed) != 0 {
+		t.Errorf("expected no outbox events, got %d sent and %d failed", len(collector.sent), len(collector.failed))
+	}
+}
+
+func TestOutboxRetryAndGiveUp(t *testing.T) {
+	ctx := context.Background()
+	cli := newTestStoreClient(t)
+	var collector outboxEventCollector
+	cli.AddEventHandler(collector.handle)
+
+	// Sending to a device JID fails with ErrRecipientADJID, which isn't a transient error.
+	to := types.NewJID("1111111111", types.DefaultUserServer)
+	to.Device = 2
+	id, err := cli.EnqueueMessage(ctx, to, &waE2E.Message{Conversation: proto.String("hello")})
+	if err != nil {
+		t.Fatalf("failed to enqueue message: %v", err)
+	}
+	for attempt := 1; attempt < OutboxMaxAttempts; attempt++ {
+		cli.flushOutbox(ctx)
+		msgs := getOutbox(t, cli)
+		if len(msgs) != 1 {
+			t.Fatalf("expected message to stay in outbox after attempt #%d, got %d messages", attempt, len(msgs))
+		} else if msgs[0].ID != id {
+			t.Fatalf("expected message ID to stay %s, got %s", id, msgs[0].ID)
+		} else if msgs[0].Attempts != attempt {
+			t.Fatalf("expected %d attempts, got %d", attempt, msgs[0].Attempts)
+		}
+		if len(collector.failed) != 0 {
+			t.Fatalf("message was dropped after only %d attempts", attempt)
+		}
+	}
+	cli.flushOutbox(ctx)
+	if msgs := getOutbox(t, cli); len(msgs) != 0 {
+		t.Fatalf("expected message to be dropped after %d attempts, got %d messages", OutboxMaxAttempts, len(msgs))
+	}
+	if len(collector.failed) != 1 {
+		t.Fatalf("expected 1 failed event, got %d", len(collector.failed))
+	}
+	evt := collector.failed[0]
+	if evt.ID != id || evt.To != to || !errors.Is(evt.Error, ErrRecipientADJID) {
+		t.Errorf("unexpected failed event: id=%s to=%s err=%v", evt.ID, evt.To, evt.Error)
+	}
+	if len(collector.sent) != 0 {
+		t.Errorf("expected no sent events, got %d", len(collector.sent))
+	}
+}
+
+func TestOutboxDropsInvalidMessage(t *testing.T) {
+	ctx := context.Background()
+	cli := newTestStoreClient(t)
+	var collector outboxEventCollector
+	cli.AddEventHandler(collector.handle)
+
+	to := types.NewJID("1111111111", types.DefaultUserServer)
+	err := cli.Store.Outbox.PutOutboxMessage(ctx, &store.OutboxMessage{
+		ID:      "INVALID",
+		To:      to,
+		Message: []byte{0xff},
+	})
+	if err != nil {
+		t.Fatalf("failed to store message: %v", err)
+	}
+	cli.flushOutbox(ctx)
+	if msgs := getOutbox(t, cli); len(msgs) != 0 {
+		t.Fatalf("expected invalid message to be dropped, got %d messages", len(msgs))
+	}
+	if len(collector.failed) != 1 || collector.failed[0].ID != "INVALID" {
+		t.Fatalf("expected a failed event for the invalid message, got %+v", collector.failed)
+	}
+}
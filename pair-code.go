@@ -22,6 +22,7 @@ import (
 	"golang.org/x/crypto/pbkdf2"
 
 	waBinary "go.mau.fi/whatsmeow/binary"
+	"go.mau.fi/whatsmeow/store"
 	"go.mau.fi/whatsmeow/types"
 	"go.mau.fi/whatsmeow/util/hkdfutil"
 	"go.mau.fi/whatsmeow/util/keys"
@@ -91,6 +92,10 @@ func (cli *Client) PairPhone(ctx context.Context, phone string, showPushNotifica
 	if cli == nil {
 		return "", ErrClientIsNil
 	}
+	noisePub := cli.Store.GetNoisePublicKey()
+	if noisePub == nil {
+		return "", store.ErrNoNoiseKey
+	}
 	ephemeralKeyPair, ephemeralKey, encodedLinkingCode := generateCompanionEphemeralKey()
 	phone = notNumbers.ReplaceAllString(phone, "")
 	if len(phone) <= 6 {
@@ -114,7 +119,7 @@ func (cli *Client) PairPhone(ctx context.Context, phone string, showPushNotifica
 			},
 			Content: []waBinary.Node{
 				{Tag: "link_code_pairing_wrapped_companion_ephemeral_pub", Content: ephemeralKey},
-				{Tag: "companion_server_auth_key_pub", Content: cli.Store.NoiseKey.Pub[:]},
+				{Tag: "companion_server_auth_key_pub", Content: noisePub[:]},
 				{Tag: "companion_platform_id", Content: strconv.Itoa(int(clientType))},
 				{Tag: "companion_platform_display", Content: clientDisplayName},
 				{Tag: "link_code_pairing_nonce", Content: []byte{0}},
@@ -13,13 +13,16 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"fmt"
+	mathRand "math/rand/v2"
 	"strings"
 
 	"go.mau.fi/libsignal/ecc"
+	"go.mau.fi/util/random"
 	"google.golang.org/protobuf/proto"
 
 	waBinary "go.mau.fi/whatsmeow/binary"
 	"go.mau.fi/whatsmeow/proto/waAdv"
+	"go.mau.fi/whatsmeow/store"
 	"go.mau.fi/whatsmeow/types"
 	"go.mau.fi/whatsmeow/types/events"
 	"go.mau.fi/whatsmeow/util/keys"
@@ -59,6 +62,11 @@ func (cli *Client) handlePairDevice(node *waBinary.Node) {
 		cli.Log.Warnf("Failed to send acknowledgement for pair-device request: %v", err)
 	}
 
+	noisePub := cli.Store.GetNoisePublicKey()
+	if noisePub == nil {
+		cli.Log.Errorf("Can't generate QR codes: %v", store.ErrNoNoiseKey)
+		return
+	}
 	evt := &events.QR{Codes: make([]string, 0, len(pairDevice.GetChildren()))}
 	for i, child := range pairDevice.GetChildren() {
 		if child.Tag != "ref" {
@@ -70,14 +78,14 @@ func (cli *Client) handlePairDevice(node *waBinary.Node) {
 			cli.Log.Warnf("pair-device node contains unexpected child content type %T at index %d", child, i)
 			continue
 		}
-		evt.Codes = append(evt.Codes, cli.makeQRData(string(content)))
+		evt.Codes = append(evt.Codes, cli.makeQRData(string(content), noisePub))
 	}
 
 	cli.dispatchEvent(evt)
 }
 
-func (cli *Client) makeQRData(ref string) string {
-	noise := base64.StdEncoding.EncodeToString(cli.Store.NoiseKey.Pub[:])
+func (cli *Client) makeQRData(ref string, noisePub *[32]byte) string {
+	noise := base64.StdEncoding.EncodeToString(noisePub[:])
 	identity := base64.StdEncoding.EncodeToString(cli.Store.IdentityKey.Pub[:])
 	adv := base64.StdEncoding.EncodeToString(cli.Store.AdvSecretKey)
 	return strings.Join([]string{ref, noise, identity, adv}, ",")
@@ -270,3 +278,35 @@ func (cli *Client) sendPairError(id string, code int, text string) {
 		cli.Log.Errorf("Failed to send pair error node: %v", err)
 	}
 }
+
+// RelinkDevice prepares a logged out device for pairing again without losing local data like contacts,
+// chat settings and message secrets. Only the encryption keys and the data tied to them are reset.
+//
+// This is meant to be used with KeepDataOnLogout after receiving an events.LoggedOut event. After calling this,
+// connect normally and pair using the QR code or a pairing code. When the pairing succeeds, the local data of
+// the old device is moved to the new device (as long as the same account is linked).
+//
+// The relink state is only kept in memory, so the new pairing must happen before the process is restarted.
+func (cli *Client) RelinkDevice() error {
+	if cli == nil {
+		return ErrClientIsNil
+	} else if cli.IsConnected() {
+		return ErrAlreadyConnected
+	} else if cli.Store.ID == nil {
+		return ErrNotLoggedIn
+	}
+	oldID := *cli.Store.ID
+	cli.Store.RelinkFrom = &oldID
+	cli.Store.ID = nil
+	cli.Store.LID = types.EmptyJID
+	cli.Store.Account = nil
+	if cli.Store.ExternalNoiseKey == nil {
+		cli.Store.NoiseKey = keys.NewKeyPair()
+	}
+	cli.Store.IdentityKey = keys.NewKeyPair()
+	cli.Store.SignedPreKey = cli.Store.IdentityKey.CreateSignedPreKey(1)
+	cli.Store.RegistrationID = mathRand.Uint32()
+	cli.Store.AdvSecretKey = random.Bytes(32)
+	cli.Log.Infof("Prepared %s for relinking, local data will be kept after pairing", oldID)
+	return nil
+}
@@ -126,11 +126,21 @@ func (cli *Client) SubscribePresence(jid types.JID) error {
 // SendChatPresence updates the user's typing status in a specific chat.
 //
 // The media parameter can be set to indicate the user is recording media (like a voice message) rather than typing a text message.
+// To show the recording indicator, send the composing state with audio media, and stop it by sending the paused state:
+//
+//	cli.SendChatPresence(chatJID, types.ChatPresenceComposing, types.ChatPresenceMediaAudio)
+//	// ... record the voice message ...
+//	cli.SendChatPresence(chatJID, types.ChatPresencePaused, types.ChatPresenceMediaText)
 func (cli *Client) SendChatPresence(jid types.JID, state types.ChatPresence, media types.ChatPresenceMedia) error {
 	ownID := cli.getOwnID()
 	if ownID.IsEmpty() {
 		return ErrNotLoggedIn
 	}
+	if state != types.ChatPresenceComposing && state != types.ChatPresencePaused {
+		return fmt.Errorf("unknown chat presence state %q", state)
+	} else if media != types.ChatPresenceMediaText && media != types.ChatPresenceMediaAudio {
+		return fmt.Errorf("unknown chat presence media %q", media)
+	}
 	content := []waBinary.Node{{Tag: string(state)}}
 	if state == types.ChatPresenceComposing && len(media) > 0 {
 		content[0].Attrs = waBinary.Attrs{
@@ -0,0 +1,172 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"sync"
+	"time"
+
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// PresenceState contains the last known presence of a user.
+type PresenceState struct {
+	Available bool
+	// The time when the user was last online. This may be the zero value if the user has hid their last seen time.
+	LastSeen time.Time
+	// The time when the last presence update for the user was received. Zero if no updates have been received yet.
+	UpdatedAt time.Time
+}
+
+// PresenceTracker keeps presence subscriptions alive and remembers the last known presence of each subscribed user.
+//
+// Presence subscriptions expire on the server, and they're also lost when reconnecting,
+// so the tracker renews all subscriptions periodically and after every reconnection.
+//
+//	tracker := whatsmeow.NewPresenceTracker(cli)
+//	err := tracker.Subscribe(userJID)
+//	...
+//	state := tracker.Get(userJID)
+//
+// Note that the WhatsApp servers only send presence updates to clients that are marked as online (see Client.SendPresence).
+type PresenceTracker struct {
+	// OnChange is called with a copy of the new state whenever a presence update for a subscribed user is received.
+	OnChange func(user types.JID, state PresenceState)
+
+	cli       *Client
+	handlerID uint32
+	lock      sync.Mutex
+	presences map[types.JID]*PresenceState
+	stop      chan struct{}
+	stopOnce  sync.Once
+}
+
+// PresenceRenewInterval is the interval at which PresenceTracker renews presence subscriptions.
+var PresenceRenewInterval = 5 * time.Minute
+
+// NewPresenceTracker creates a new presence tracker, registers it as an event handler in the given client
+// and starts the background loop that renews subscriptions.
+//
+// Call Close to stop the tracker when it's no longer needed.
+func NewPresenceTracker(cli *Client) *PresenceTracker {
+	tracker := &PresenceTracker{
+		cli:       cli,
+		presences: make(map[types.JID]*PresenceState),
+		stop:      make(chan struct{}),
+	}
+	tracker.handlerID = cli.AddEventHandler(tracker.handleEvent)
+	go tracker.renewLoop()
+	return tracker
+}
+
+// Close unregisters the tracker's event handler and stops renewing subscriptions.
+// This is safe to call from inside an event handler.
+func (pt *PresenceTracker) Close() {
+	pt.stopOnce.Do(func() {
+		close(pt.stop)
+		pt.cli.RemoveEventHandler(pt.handlerID)
+	})
+}
+
+// Subscribe subscribes to the presence of the given user and keeps the subscription alive until Unsubscribe is called.
+//
+// The user is tracked even if the initial subscription fails, so it'll be retried on the next renewal.
+func (pt *PresenceTracker) Subscribe(user types.JID) error {
+	user = user.ToNonAD()
+	pt.lock.Lock()
+	if _, ok := pt.presences[user]; !ok {
+		pt.presences[user] = &PresenceState{}
+	}
+	pt.lock.Unlock()
+	return pt.cli.SubscribePresence(user)
+}
+
+// Unsubscribe stops tracking the presence of the given user.
+//
+// WhatsApp doesn't have a way to explicitly unsubscribe, so presence updates may still be received
+// until the server-side subscription expires, but they won't be tracked.
+func (pt *PresenceTracker) Unsubscribe(user types.JID) {
+	pt.lock.Lock()
+	delete(pt.presences, user.ToNonAD())
+	pt.lock.Unlock()
+}
+
+// Get returns the last known presence of the given user. The second return value is false if the user isn't subscribed.
+func (pt *PresenceTracker) Get(user types.JID) (PresenceState, bool) {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	state, ok := pt.presences[user.ToNonAD()]
+	if !ok {
+		return PresenceState{}, false
+	}
+	return *state, true
+}
+
+// Subscribed returns the list of users whose presence is currently tracked.
+func (pt *PresenceTracker) Subscribed() []types.JID {
+	pt.lock.Lock()
+	defer pt.lock.Unlock()
+	users := make([]types.JID, 0, len(pt.presences))
+	for user := range pt.presences {
+		users = append(users, user)
+	}
+	return users
+}
+
+func (pt *PresenceTracker) renewAll() {
+	if !pt.cli.IsLoggedIn() {
+		return
+	}
+	for _, user := range pt.Subscribed() {
+		err := pt.cli.SubscribePresence(user)
+		if err != nil {
+			pt.cli.Log.Warnf("Failed to renew presence subscription of %s: %v", user, err)
+		}
+	}
+}
+
+func (pt *PresenceTracker) renewLoop() {
+	ticker := time.NewTicker(PresenceRenewInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			pt.renewAll()
+		case <-pt.stop:
+			return
+		}
+	}
+}
+
+func (pt *PresenceTracker) handleEvent(rawEvt any) {
+	switch evt := rawEvt.(type) {
+	case *events.Connected:
+		// Subscriptions don't survive reconnections, so renew them all immediately
+		go pt.renewAll()
+	case *events.Presence:
+		user := evt.From.ToNonAD()
+		pt.lock.Lock()
+		state, ok := pt.presences[user]
+		if !ok {
+			pt.lock.Unlock()
+			return
+		}
+		state.Available = !evt.Unavailable
+		if !evt.LastSeen.IsZero() {
+			state.LastSeen = evt.LastSeen
+		} else if evt.Unavailable {
+			state.LastSeen = time.Time{}
+		}
+		state.UpdatedAt = time.Now()
+		stateCopy := *state
+		pt.lock.Unlock()
+		if pt.OnChange != nil {
+			pt.OnChange(user, stateCopy)
+		}
+	}
+}
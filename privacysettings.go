@@ -106,6 +106,10 @@ func (cli *Client) SetPrivacySetting(ctx context.Context, name types.PrivacySett
 }
 
 // SetDefaultDisappearingTimer will set the default disappearing message timer.
+//
+// The last known value is stored in Client.Store.DefaultDisappearingTimer. The stored value is only updated
+// when this method is called or when the server sends a notification about the timer being changed from another
+// device. It's not fetched from the server on connect, so it will be zero after pairing until either of those happen.
 func (cli *Client) SetDefaultDisappearingTimer(timer time.Duration) (err error) {
 	_, err = cli.sendIQ(infoQuery{
 		Namespace: "disappearing_mode",
@@ -118,9 +122,38 @@ func (cli *Client) SetDefaultDisappearingTimer(timer time.Duration) (err error)
 			},
 		}},
 	})
+	if err == nil {
+		cli.updateDefaultDisappearingTimer(context.TODO(), timer)
+	}
 	return
 }
 
+func (cli *Client) updateDefaultDisappearingTimer(ctx context.Context, timer time.Duration) {
+	if cli.Store.DefaultDisappearingTimer == timer {
+		return
+	}
+	cli.Store.DefaultDisappearingTimer = timer
+	err := cli.Store.Save(ctx)
+	if err != nil {
+		cli.Log.Errorf("Failed to save device store after updating default disappearing timer: %v", err)
+	}
+}
+
+func (cli *Client) handleDisappearingModeNotification(ctx context.Context, node *waBinary.Node) {
+	child, ok := node.GetOptionalChildByTag("disappearing_mode")
+	if !ok {
+		cli.Log.Warnf("Disappearing mode notification doesn't contain disappearing_mode element")
+		return
+	}
+	ag := child.AttrGetter()
+	duration := ag.Uint64("duration")
+	if !ag.OK() {
+		cli.Log.Warnf("Failed to parse disappearing mode notification: %v", ag.Error())
+		return
+	}
+	cli.updateDefaultDisappearingTimer(ctx, time.Duration(duration)*time.Second)
+}
+
 func (cli *Client) parsePrivacySettings(privacyNode *waBinary.Node, settings *types.PrivacySettings) *events.PrivacySettings {
 	var evt events.PrivacySettings
 	for _, child := range privacyNode.GetChildren() {
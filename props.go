@@ -0,0 +1,84 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"context"
+	"time"
+
+	waBinary "go.mau.fi/whatsmeow/binary"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// TryFetchServerProps fetches the server props (configuration values like limits and feature flags),
+// either from the in-memory cache or from the server.
+func (cli *Client) TryFetchServerProps(ctx context.Context, ignoreCache bool) (*types.Props, error) {
+	if cli == nil {
+		return nil, ErrClientIsNil
+	} else if val := cli.serverPropsCache.Load(); val != nil && !ignoreCache {
+		return val.(*types.Props), nil
+	}
+	props, err := cli.fetchProps(ctx, "w", "2", "name", "value")
+	if err != nil {
+		return nil, err
+	}
+	cli.serverPropsCache.Store(props)
+	return props, nil
+}
+
+// TryFetchABProps fetches the A/B experiment props of the account, either from the in-memory cache or from the server.
+// The values in the returned props are keyed by the numeric config code.
+func (cli *Client) TryFetchABProps(ctx context.Context, ignoreCache bool) (*types.Props, error) {
+	if cli == nil {
+		return nil, ErrClientIsNil
+	} else if val := cli.abPropsCache.Load(); val != nil && !ignoreCache {
+		return val.(*types.Props), nil
+	}
+	props, err := cli.fetchProps(ctx, "abt", "1", "config_code", "config_value")
+	if err != nil {
+		return nil, err
+	}
+	cli.abPropsCache.Store(props)
+	return props, nil
+}
+
+func (cli *Client) fetchProps(ctx context.Context, namespace, protocol, keyAttr, valueAttr string) (*types.Props, error) {
+	resp, err := cli.sendIQ(infoQuery{
+		Context:   ctx,
+		Namespace: namespace,
+		Type:      iqGet,
+		To:        types.ServerJID,
+		Content: []waBinary.Node{{
+			Tag:   "props",
+			Attrs: waBinary.Attrs{"protocol": protocol},
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	propsNode, ok := resp.GetOptionalChildByTag("props")
+	if !ok {
+		return nil, &ElementMissingError{Tag: "props", In: "response to props query"}
+	}
+	ag := propsNode.AttrGetter()
+	props := &types.Props{
+		Hash:            ag.OptionalString("hash"),
+		RefreshInterval: time.Duration(ag.OptionalInt("refresh")) * time.Second,
+		Values:          make(map[string]string),
+	}
+	for _, child := range propsNode.GetChildren() {
+		if child.Tag != "prop" {
+			continue
+		}
+		childAG := child.AttrGetter()
+		key := childAG.OptionalString(keyAttr)
+		if key != "" {
+			props.Values[key] = childAG.OptionalString(valueAttr)
+		}
+	}
+	return props, nil
+}
@@ -149,8 +149,7 @@ func (qrc *qrChannel) handleEvent(rawEvt interface{}) {
 	} else {
 		qrc.log.Debugf("Got status %+v, but channel is already closed", outputType)
 	}
-	// Has to be done in background because otherwise there's a deadlock with eventHandlersLock
-	go qrc.cli.RemoveEventHandler(qrc.handlerID)
+	qrc.cli.RemoveEventHandler(qrc.handlerID)
 }
 
 // GetQRChannel returns a channel that automatically outputs a new QR code when the previous one expires.
@@ -162,6 +162,9 @@ func (cli *Client) sendAck(node *waBinary.Node) {
 // You can mark multiple messages as read at the same time, but only if the messages were sent by the same user.
 // To mark messages by different users as read, you must call MarkRead multiple times (once for each user).
 //
+// If Client.DisableReadReceipts is set or read receipts are disabled in the privacy settings, the message is only
+// marked as read on your own devices and the sender won't receive a read receipt.
+//
 // To mark a voice message as played, specify types.ReceiptTypePlayed as the last parameter.
 // Providing more than one receipt type will panic: the parameter is only a vararg for backwards compatibility.
 func (cli *Client) MarkRead(ids []types.MessageID, timestamp time.Time, chat, sender types.JID, receiptTypeExtra ...types.ReceiptType) error {
@@ -183,7 +186,7 @@ func (cli *Client) MarkRead(ids []types.MessageID, timestamp time.Time, chat, se
 			"t":    timestamp.Unix(),
 		},
 	}
-	if chat.Server == types.NewsletterServer || cli.GetPrivacySettings(context.TODO()).ReadReceipts == types.PrivacySettingNone {
+	if chat.Server == types.NewsletterServer || cli.DisableReadReceipts || cli.GetPrivacySettings(context.TODO()).ReadReceipts == types.PrivacySettingNone {
 		switch receiptType {
 		case types.ReceiptTypeRead:
 			node.Attrs["type"] = string(types.ReceiptTypeReadSelf)
@@ -207,6 +210,37 @@ func (cli *Client) MarkRead(ids []types.MessageID, timestamp time.Time, chat, se
 	return cli.sendNode(node)
 }
 
+// MarkChatRead sends read receipts for all the given messages in a chat, sending one receipt node per sender
+// with the message IDs batched inside it, rather than one receipt per message.
+//
+// Messages sent by yourself and messages with a timestamp after upTo are skipped, so this can be used to mark
+// an entire chat as read up to a certain point by passing all the known messages in the chat.
+// If upTo is zero, all the given messages are marked as read. The read at time in the receipts is always the current time.
+//
+// This only sends receipts to the senders, it doesn't change the unread status of the chat on your other devices.
+func (cli *Client) MarkChatRead(chat types.JID, messages []*types.MessageInfo, upTo time.Time) error {
+	idsBySender := make(map[types.JID][]types.MessageID)
+	var senders []types.JID
+	for _, msg := range messages {
+		if msg.IsFromMe || (!upTo.IsZero() && msg.Timestamp.After(upTo)) {
+			continue
+		}
+		sender := msg.Sender.ToNonAD()
+		if _, ok := idsBySender[sender]; !ok {
+			senders = append(senders, sender)
+		}
+		idsBySender[sender] = append(idsBySender[sender], msg.ID)
+	}
+	now := time.Now()
+	for _, sender := range senders {
+		err := cli.MarkRead(idsBySender[sender], now, chat, sender)
+		if err != nil {
+			return fmt.Errorf("failed to mark messages from %s as read: %w", sender, err)
+		}
+	}
+	return nil
+}
+
 // SetForceActiveDeliveryReceipts will force the client to send normal delivery
 // receipts (which will show up as the two gray ticks on WhatsApp), even if the
 // client isn't marked as online.
@@ -234,6 +268,24 @@ func (cli *Client) SetForceActiveDeliveryReceipts(active bool) {
 }
 
 func (cli *Client) sendMessageReceipt(info *types.MessageInfo) {
+	if cli.ManualDeliveryReceipts && !info.IsFromMe {
+		return
+	}
+	err := cli.SendMessageReceipt(info)
+	if err != nil {
+		cli.Log.Warnf("Failed to send receipt for %s: %v", info.ID, err)
+	}
+}
+
+// SendMessageReceipt sends a delivery receipt for the given message.
+//
+// This is done automatically for all successfully decrypted messages, unless ManualDeliveryReceipts is set,
+// in which case applications must call this themselves once they've processed the message.
+// Until the receipt is sent, the sender will only see a single gray tick.
+func (cli *Client) SendMessageReceipt(info *types.MessageInfo) error {
+	if cli == nil {
+		return ErrClientIsNil
+	}
 	attrs := waBinary.Attrs{
 		"id": info.ID,
 	}
@@ -251,11 +303,8 @@ func (cli *Client) sendMessageReceipt(info *types.MessageInfo) {
 		// Override the to attribute with the JID version with a device number
 		attrs["to"] = info.Sender
 	}
-	err := cli.sendNode(waBinary.Node{
+	return cli.sendNode(waBinary.Node{
 		Tag:   "receipt",
 		Attrs: attrs,
 	})
-	if err != nil {
-		cli.Log.Warnf("Failed to send receipt for %s: %v", info.ID, err)
-	}
 }
@@ -0,0 +1,98 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+
+	waBinary "go.mau.fi/whatsmeow/binary"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+)
+
+// SpamFlow is the place in the UI where a spam report was made from.
+type SpamFlow string
+
+const (
+	SpamFlowMessageMenu SpamFlow = "MessageMenu"
+	SpamFlowChatInfo    SpamFlow = "ChatInfoReport"
+	SpamFlowGroupInfo   SpamFlow = "GroupInfoReport"
+)
+
+// ReportSpam reports the given chat as spam to WhatsApp, optionally including some messages from the chat as evidence.
+//
+// The messages should be the ones received as events.Message in the same chat. If a message contains a message secret,
+// a reporting token is included so that the server can verify the reported content.
+// This only sends the report: use UpdateBlocklist if you also want to block the sender.
+func (cli *Client) ReportSpam(ctx context.Context, chat types.JID, flow SpamFlow, messages ...*events.Message) error {
+	if cli == nil {
+		return ErrClientIsNil
+	} else if chat.IsEmpty() {
+		return fmt.Errorf("no chat specified")
+	}
+	if flow == "" {
+		flow = SpamFlowMessageMenu
+	}
+	children := make([]waBinary.Node, 0, len(messages))
+	for _, msg := range messages {
+		if msg.Info.Chat != chat {
+			return fmt.Errorf("message %s is not in the reported chat", msg.Info.ID)
+		}
+		node, err := cli.buildSpamReportMessage(msg)
+		if err != nil {
+			return err
+		}
+		children = append(children, node)
+	}
+	_, err := cli.sendIQ(infoQuery{
+		Context:   ctx,
+		Namespace: "spam",
+		Type:      iqSet,
+		To:        types.ServerJID,
+		Content: []waBinary.Node{{
+			Tag: "spam_list",
+			Attrs: waBinary.Attrs{
+				"spam_flow": string(flow),
+				"jid":       chat,
+			},
+			Content: children,
+		}},
+	})
+	return err
+}
+
+func (cli *Client) buildSpamReportMessage(msg *events.Message) (waBinary.Node, error) {
+	attrs := waBinary.Attrs{
+		"id":   msg.Info.ID,
+		"t":    msg.Info.Timestamp.Unix(),
+		"from": msg.Info.Chat,
+	}
+	if msg.Info.IsGroup {
+		attrs["participant"] = msg.Info.Sender.ToNonAD()
+	}
+	if msg.Info.Type != "" {
+		attrs["type"] = msg.Info.Type
+	}
+	node := waBinary.Node{Tag: "message", Attrs: attrs}
+	rawMessage := msg.RawMessage
+	if rawMessage == nil {
+		rawMessage = msg.Message
+	}
+	if len(rawMessage.GetMessageContextInfo().GetMessageSecret()) > 0 {
+		plaintext, err := proto.Marshal(rawMessage)
+		if err != nil {
+			return node, fmt.Errorf("failed to marshal message %s for reporting token: %w", msg.Info.ID, err)
+		}
+		node.Content = []waBinary.Node{
+			cli.getMessageReportingToken(plaintext, rawMessage, msg.Info.Sender.ToNonAD(), msg.Info.Chat, msg.Info.ID),
+		}
+	}
+	return node, nil
+}
@@ -8,6 +8,7 @@ package whatsmeow
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"time"
@@ -50,7 +51,38 @@ func (cli *Client) clearResponseWaiters(node *waBinary.Node) {
 		}
 	}
 	cli.responseWaiters = make(map[string]chan<- *waBinary.Node)
+	cleanups := cli.responseCleanups
+	cli.responseCleanups = make(map[string]func())
 	cli.responseWaitersLock.Unlock()
+	for _, cleanup := range cleanups {
+		cleanup()
+	}
+}
+
+// addResponseCleanup registers a function to be called when the response waiter for the given request ID
+// is done, i.e. when the response is received, the waiter expires or the connection is closed.
+// If the waiter is already done, the function is called immediately.
+func (cli *Client) addResponseCleanup(reqID string, cleanup func()) {
+	cli.responseWaitersLock.Lock()
+	_, ok := cli.responseWaiters[reqID]
+	if ok {
+		cli.responseCleanups[reqID] = cleanup
+	}
+	cli.responseWaitersLock.Unlock()
+	if !ok {
+		cleanup()
+	}
+}
+
+// popResponseCleanup removes and returns the cleanup function of the given request ID.
+// The response waiters lock must be held when calling this.
+func (cli *Client) popResponseCleanup(reqID string) func() {
+	cleanup, ok := cli.responseCleanups[reqID]
+	if !ok {
+		return func() {}
+	}
+	delete(cli.responseCleanups, reqID)
+	return cleanup
 }
 
 func (cli *Client) waitResponse(reqID string) chan *waBinary.Node {
@@ -65,7 +97,21 @@ func (cli *Client) cancelResponse(reqID string, ch chan *waBinary.Node) {
 	cli.responseWaitersLock.Lock()
 	close(ch)
 	delete(cli.responseWaiters, reqID)
+	cleanup := cli.popResponseCleanup(reqID)
 	cli.responseWaitersLock.Unlock()
+	cleanup()
+}
+
+// expireResponse closes the response waiter for the given request ID, unless a response has already been delivered to it.
+func (cli *Client) expireResponse(reqID string) {
+	cli.responseWaitersLock.Lock()
+	if ch, ok := cli.responseWaiters[reqID]; ok {
+		close(ch)
+		delete(cli.responseWaiters, reqID)
+	}
+	cleanup := cli.popResponseCleanup(reqID)
+	cli.responseWaitersLock.Unlock()
+	cleanup()
 }
 
 func (cli *Client) receiveResponse(data *waBinary.Node) bool {
@@ -80,8 +126,10 @@ func (cli *Client) receiveResponse(data *waBinary.Node) bool {
 		return false
 	}
 	delete(cli.responseWaiters, id)
+	cleanup := cli.popResponseCleanup(id)
 	cli.responseWaitersLock.Unlock()
 	waiter <- data
+	cleanup()
 	return true
 }
 
@@ -143,10 +191,15 @@ func (cli *Client) sendIQAsync(query infoQuery) (<-chan *waBinary.Node, error) {
 
 const defaultRequestTimeout = 75 * time.Second
 
+// shouldResendIQ returns true if the given query should wait for a reconnection instead of failing immediately.
+// Only get queries are resent, as they're idempotent.
+func (cli *Client) shouldResendIQ(query *infoQuery) bool {
+	return cli.ResendIQsOnReconnect && query.Type == iqGet && !query.NoRetry
+}
+
 func (cli *Client) sendIQ(query infoQuery) (*waBinary.Node, error) {
-	resChan, data, err := cli.sendIQAsyncAndGetData(&query)
-	if err != nil {
-		return nil, err
+	if query.Timeout == 0 {
+		query.Timeout = cli.DefaultIQTimeout
 	}
 	if query.Timeout == 0 {
 		query.Timeout = defaultRequestTimeout
@@ -154,29 +207,124 @@ func (cli *Client) sendIQ(query infoQuery) (*waBinary.Node, error) {
 	if query.Context == nil {
 		query.Context = context.Background()
 	}
+	deadline := time.Now().Add(query.Timeout)
+	resChan, data, err := cli.sendIQAsyncAndGetData(&query)
+	if errors.Is(err, ErrNotConnected) && cli.shouldResendIQ(&query) {
+		cli.Log.Debugf("Waiting for connection to send info query %s (%s)", query.ID, query.Namespace)
+		if cli.WaitForConnection(time.Until(deadline)) {
+			resChan, data, err = cli.sendIQAsyncAndGetData(&query)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
 	select {
 	case res := <-resChan:
 		if isDisconnectNode(res) {
 			if query.NoRetry {
 				return nil, &DisconnectedError{Action: "info query", Node: res}
 			}
+			if cli.shouldResendIQ(&query) && !isAuthErrorDisconnect(res) {
+				// Wait for the whole timeout instead of the few seconds retryFrame waits by default
+				cli.WaitForConnection(time.Until(deadline))
+			}
 			res, err = cli.retryFrame("info query", query.ID, data, res, query.Context, query.Timeout)
 			if err != nil {
 				return nil, err
 			}
 		}
-		resType, _ := res.Attrs["type"].(string)
-		if res.Tag != "iq" || (resType != "result" && resType != "error") {
-			return res, &IQError{RawNode: res}
-		} else if resType == "error" {
-			return res, parseIQError(res)
-		}
-		return res, nil
+		return checkIQResponse(res)
 	case <-query.Context.Done():
 		return nil, query.Context.Err()
-	case <-time.After(query.Timeout):
+	case <-time.After(time.Until(deadline)):
+		return nil, ErrIQTimedOut
+	}
+}
+
+func checkIQResponse(res *waBinary.Node) (*waBinary.Node, error) {
+	resType, _ := res.Attrs["type"].(string)
+	if res.Tag != "iq" || (resType != "result" && resType != "error") {
+		return res, &IQError{RawNode: res}
+	} else if resType == "error" {
+		return res, parseIQError(res)
+	}
+	return res, nil
+}
+
+// IQ contains the parameters of an info query sent with SendIQAsync.
+type IQ struct {
+	Namespace string
+	// Type is either "get" or "set".
+	Type    string
+	To      types.JID
+	Target  types.JID
+	Content any
+
+	// The time after which the query is abandoned. Defaults to Client.DefaultIQTimeout or 75 seconds.
+	Timeout time.Duration
+}
+
+// SendIQAsync sends an info query without waiting for the response.
+//
+// The returned channel will receive exactly one value: the response node, a disconnection node
+// if the connection dropped before the response arrived, or nil if the timeout passed or the context
+// was canceled first. Use ParseIQResponse to turn the value into a result node or an error.
+//
+// Unlike the other query methods, this doesn't block a goroutine per query, so it can be used to have
+// many queries in flight at once, e.g. when fetching profile pictures in bulk. Queries are never retried.
+func (cli *Client) SendIQAsync(ctx context.Context, query IQ) (<-chan *waBinary.Node, error) {
+	if cli == nil {
+		return nil, ErrClientIsNil
+	} else if query.Type != string(iqGet) && query.Type != string(iqSet) {
+		return nil, fmt.Errorf("invalid info query type %q", query.Type)
+	}
+	if query.Timeout == 0 {
+		query.Timeout = cli.DefaultIQTimeout
+	}
+	if query.Timeout == 0 {
+		query.Timeout = defaultRequestTimeout
+	}
+	iq := infoQuery{
+		Namespace: query.Namespace,
+		Type:      infoQueryType(query.Type),
+		To:        query.To,
+		Target:    query.Target,
+		Content:   query.Content,
+	}
+	ch, _, err := cli.sendIQAsyncAndGetData(&iq)
+	if err != nil {
+		return nil, err
+	}
+	// The waiter channel is closed (i.e. nil is received) if the response doesn't arrive in time
+	timer := time.AfterFunc(query.Timeout, func() {
+		cli.expireResponse(iq.ID)
+	})
+	stopCtxFunc := func() bool { return false }
+	if ctx != nil {
+		stopCtxFunc = context.AfterFunc(ctx, func() {
+			cli.expireResponse(iq.ID)
+		})
+	}
+	// Release the timer and context callback as soon as the waiter is done, so that long-lived
+	// contexts don't accumulate callbacks for queries that have already finished.
+	cli.addResponseCleanup(iq.ID, func() {
+		timer.Stop()
+		stopCtxFunc()
+	})
+	return ch, nil
+}
+
+// ParseIQResponse converts a value received from a channel returned by SendIQAsync into the result node or an error.
+//
+// A nil node results in ErrIQTimedOut, disconnections result in a *DisconnectedError
+// and error responses from the server result in an *IQError.
+func ParseIQResponse(res *waBinary.Node) (*waBinary.Node, error) {
+	if res == nil {
 		return nil, ErrIQTimedOut
+	} else if isDisconnectNode(res) {
+		return nil, &DisconnectedError{Action: "info query", Node: res}
 	}
+	return checkIQResponse(res)
 }
 
 func (cli *Client) retryFrame(reqType, id string, data []byte, origResp *waBinary.Node, ctx context.Context, timeout time.Duration) (*waBinary.Node, error) {
@@ -204,6 +352,8 @@ func (cli *Client) retryFrame(reqType, id string, data []byte, origResp *waBinar
 		cli.cancelResponse(id, respChan)
 		return nil, err
 	}
+	// The node isn't decoded here, so resent frames are only counted in the frame and byte totals
+	cli.stats.recordSent("", len(data))
 	var resp *waBinary.Node
 	timeoutChan := make(<-chan time.Time, 1)
 	if timeout > 0 {
@@ -42,8 +42,12 @@ const WebMessageIDPrefix = "3EB0"
 //
 //	msgID := cli.GenerateMessageID()
 //	cli.SendMessage(context.Background(), targetJID, &waE2E.Message{...}, whatsmeow.SendRequestExtra{ID: msgID})
+//
+// If Client.GenerateMessageIDHook is set, it will be used instead of the default generator.
 func (cli *Client) GenerateMessageID() types.MessageID {
-	if cli != nil && cli.MessengerConfig != nil {
+	if cli != nil && cli.GenerateMessageIDHook != nil {
+		return cli.GenerateMessageIDHook()
+	} else if cli != nil && cli.MessengerConfig != nil {
 		return types.MessageID(strconv.FormatInt(GenerateFacebookMessageID(), 10))
 	}
 	data := make([]byte, 8, 8+20+16)
@@ -147,6 +151,22 @@ type SendRequestExtra struct {
 	Timeout time.Duration
 	// When sending media to newsletters, the Handle field returned by the file upload.
 	MediaHandle string
+	// If set, the message will be sent as a disappearing message with the given timer.
+	// This should match the chat's current disappearing timer (e.g. GroupInfo.DisappearingTimer for groups).
+	DisappearingTimer time.Duration
+	// The time when the chat's disappearing timer was last changed (optional, only used with DisappearingTimer).
+	DisappearingTimerSetAt time.Time
+	// If true, image, video and audio messages will be sent as view-once messages.
+	ViewOnce bool
+	// An optional key that makes retrying the send idempotent. If a message was already successfully sent
+	// with the same key, it won't be sent again, and the response of the original send is returned instead.
+	// If a previous send with the same key was interrupted, the message is resent with the same message ID.
+	// Concurrent sends with the same key are serialized. Reusing a key for a different chat returns ErrIdempotencyKeyReused.
+	// Keys are stored in the database for 7 days.
+	IdempotencyKey string
+	// When sending to types.StatusBroadcastJID, the audience to send the status to.
+	// If not set, the account's default status privacy setting is used.
+	StatusPrivacy *types.StatusPrivacy
 
 	Meta *types.MsgMetaInfo
 }
@@ -213,6 +233,20 @@ func (cli *Client) SendMessage(ctx context.Context, to types.JID, message *waE2E
 	}
 	resp.ID = req.ID
 
+	if req.IdempotencyKey != "" {
+		defer cli.lockIdempotencyKey(req.IdempotencyKey)()
+		var alreadySent bool
+		alreadySent, err = cli.checkIdempotencyKey(ctx, to, &req, &resp)
+		if err != nil || alreadySent {
+			return
+		}
+		defer func() {
+			if err == nil {
+				cli.markIdempotencyKeySent(ctx, req.IdempotencyKey, resp.Timestamp)
+			}
+		}()
+	}
+
 	isInlineBotMode := false
 
 	if !req.InlineBotJID.IsEmpty() {
@@ -223,6 +257,24 @@ func (cli *Client) SendMessage(ctx context.Context, to types.JID, message *waE2E
 		isInlineBotMode = true
 	}
 
+	if req.DisappearingTimer > 0 {
+		// Clone the message to avoid modifying the caller's copy
+		message = proto.Clone(message).(*waE2E.Message)
+		ctxInfo := getOrCreateContextInfo(message)
+		if ctxInfo == nil {
+			err = fmt.Errorf("can't send %s message as disappearing message", getTypeFromMessage(message))
+			return
+		}
+		SetExpiration(ctxInfo, req.DisappearingTimer, req.DisappearingTimerSetAt)
+	}
+
+	if req.ViewOnce {
+		message, err = wrapViewOnce(message)
+		if err != nil {
+			return
+		}
+	}
+
 	isBotMode := isInlineBotMode || to.IsBot()
 	needsMessageSecret := isBotMode || cli.shouldIncludeReportingToken(message)
 	var extraParams nodeExtraParams
@@ -302,7 +354,7 @@ func (cli *Client) SendMessage(ctx context.Context, to types.JID, message *waE2E
 				extraParams.addressingMode = types.AddressingModePN
 			}
 		} else {
-			groupParticipants, err = cli.getBroadcastListParticipants(ctx, to)
+			groupParticipants, err = cli.getBroadcastListParticipants(ctx, to, req.StatusPrivacy)
 			if err != nil {
 				err = fmt.Errorf("failed to get broadcast list members: %w", err)
 				return
@@ -465,6 +517,10 @@ func (cli *Client) BuildRevoke(chat, sender types.JID, id types.MessageID) *waE2
 //	resp, err := cli.SendMessage(context.Background(), chat, cli.BuildReaction(chat, senderJID, targetMessageID, "🐈️")
 //
 // Note that for newsletter messages, you need to use NewsletterSendReaction instead of BuildReaction + SendMessage.
+//
+// To react to a status, pass types.StatusBroadcastJID as the chat and send the reaction to the status poster's JID:
+//
+//	resp, err := cli.SendMessage(context.Background(), posterJID, cli.BuildReaction(types.StatusBroadcastJID, posterJID, statusID, "❤️"))
 func (cli *Client) BuildReaction(chat, sender types.JID, id types.MessageID, reaction string) *waE2E.Message {
 	return &waE2E.Message{
 		ReactionMessage: &waE2E.ReactionMessage{
@@ -475,6 +531,24 @@ func (cli *Client) BuildReaction(chat, sender types.JID, id types.MessageID, rea
 	}
 }
 
+// BuildKeepInChat builds a message that keeps (or unkeeps, if keep is false) a message in a chat with disappearing messages enabled.
+// Kept messages won't disappear when the disappearing timer runs out. The built message can be sent normally using Client.SendMessage.
+//
+//	resp, err := cli.SendMessage(context.Background(), chat, cli.BuildKeepInChat(chat, senderJID, targetMessageID, true))
+func (cli *Client) BuildKeepInChat(chat, sender types.JID, id types.MessageID, keep bool) *waE2E.Message {
+	keepType := waE2E.KeepType_KEEP_FOR_ALL
+	if !keep {
+		keepType = waE2E.KeepType_UNDO_KEEP_FOR_ALL
+	}
+	return &waE2E.Message{
+		KeepInChatMessage: &waE2E.KeepInChatMessage{
+			Key:         cli.BuildMessageKey(chat, sender, id),
+			KeepType:    keepType.Enum(),
+			TimestampMS: proto.Int64(time.Now().UnixMilli()),
+		},
+	}
+}
+
 // BuildUnavailableMessageRequest builds a message to request the user's primary device to send
 // the copy of a message that this client was unable to decrypt.
 //
@@ -495,6 +569,31 @@ func (cli *Client) BuildUnavailableMessageRequest(chat, sender types.JID, id str
 	}
 }
 
+// SendPeerMessage sends the given protocol message to all other devices of the current account,
+// e.g. an app state key share built with BuildAppStateSyncKeyShare.
+//
+// To send a peer message to a single device (like the primary phone), use SendMessage with SendRequestExtra{Peer: true}.
+func (cli *Client) SendPeerMessage(ctx context.Context, message *waE2E.Message) error {
+	ownID := cli.getOwnID()
+	if ownID.IsEmpty() {
+		return ErrNotLoggedIn
+	}
+	devices, err := cli.GetUserDevicesContext(ctx, []types.JID{ownID.ToNonAD()})
+	if err != nil {
+		return fmt.Errorf("failed to get own devices: %w", err)
+	}
+	for _, device := range devices {
+		if device.Server != ownID.Server || device.Device == ownID.Device {
+			continue
+		}
+		_, err = cli.SendMessage(ctx, device, message, SendRequestExtra{Peer: true})
+		if err != nil {
+			return fmt.Errorf("failed to send peer message to %s: %w", device, err)
+		}
+	}
+	return nil
+}
+
 // BuildHistorySyncRequest builds a message to request additional history from the user's primary device.
 //
 // The built message can be sent using Client.SendMessage, but you must pass whatsmeow.SendRequestExtra{Peer: true} as the last parameter.
@@ -548,6 +647,268 @@ func (cli *Client) BuildEdit(chat types.JID, id types.MessageID, newContent *waE
 	}
 }
 
+// BuildCaptionEdit builds an edit message that changes the caption of a previously sent media message.
+// The original message should be the media message that was sent (e.g. the one passed to SendMessage).
+// The built message can be sent normally using Client.SendMessage.
+//
+//	resp, err := cli.SendMessage(context.Background(), chat, cli.BuildCaptionEdit(chat, originalMessageID, originalMessage, "new caption"))
+func (cli *Client) BuildCaptionEdit(chat types.JID, id types.MessageID, original *waE2E.Message, caption string) (*waE2E.Message, error) {
+	newContent := proto.Clone(original).(*waE2E.Message)
+	newContent.MessageContextInfo = nil
+	switch {
+	case newContent.ImageMessage != nil:
+		newContent.ImageMessage.Caption = proto.String(caption)
+	case newContent.VideoMessage != nil:
+		newContent.VideoMessage.Caption = proto.String(caption)
+	case newContent.DocumentMessage != nil:
+		newContent.DocumentMessage.Caption = proto.String(caption)
+	case newContent.GetDocumentWithCaptionMessage().GetMessage().GetDocumentMessage() != nil:
+		newContent.DocumentWithCaptionMessage.Message.DocumentMessage.Caption = proto.String(caption)
+	default:
+		return nil, fmt.Errorf("can't edit caption of %s message", getTypeFromMessage(original))
+	}
+	return cli.BuildEdit(chat, id, newContent), nil
+}
+
+// BuildReplyContext builds a ContextInfo that quotes the given message. The returned value can be set as the
+// ContextInfo of any message type that has one (e.g. ExtendedTextMessage or ImageMessage) to make it a reply.
+//
+// The info and message should be the ones from the *events.Message that is being replied to (evt.Info and evt.Message).
+// The participant is always set to the sender of the quoted message (without a device part), which is required
+// in both DMs and groups. The remote JID is only set for status broadcast replies.
+//
+// Replies to statuses must be sent to the poster's JID (evt.Info.Sender) rather than the status broadcast chat.
+func (cli *Client) BuildReplyContext(info *types.MessageInfo, original *waE2E.Message) *waE2E.ContextInfo {
+	return NewReplyContextInfo(info, original)
+}
+
+// NewReplyContextInfo is the same as Client.BuildReplyContext, but doesn't require a client.
+func NewReplyContextInfo(info *types.MessageInfo, original *waE2E.Message) *waE2E.ContextInfo {
+	quoted := proto.Clone(original).(*waE2E.Message)
+	// Device list metadata and such shouldn't be copied into the quoted message
+	quoted.MessageContextInfo = nil
+	ctxInfo := &waE2E.ContextInfo{
+		StanzaID:      proto.String(info.ID),
+		Participant:   proto.String(info.Sender.ToNonAD().String()),
+		QuotedMessage: quoted,
+	}
+	if info.Chat.Server == types.BroadcastServer {
+		ctxInfo.RemoteJID = proto.String(info.Chat.String())
+	}
+	return ctxInfo
+}
+
+// BuildForward builds a forwarded copy of the given message, which can be sent to another chat using Client.SendMessage.
+//
+// The forwarding score is incremented and replies are removed from the copy. Media is re-referenced rather than
+// re-uploaded, so the original media must still be available on the WhatsApp servers (which usually means it's
+// less than a few weeks old). Use ReuploadMedia on the built message to forward older media, or use ForwardMessage,
+// which does that automatically. View-once messages can't be forwarded.
+func (cli *Client) BuildForward(original *waE2E.Message) (*waE2E.Message, error) {
+	if original.ViewOnceMessage != nil || original.ViewOnceMessageV2 != nil || original.ViewOnceMessageV2Extension != nil {
+		return nil, fmt.Errorf("view-once messages can't be forwarded")
+	}
+	msg := proto.Clone(original).(*waE2E.Message)
+	msg.MessageContextInfo = nil
+	ctxInfo := getOrCreateContextInfo(msg)
+	if ctxInfo == nil {
+		return nil, fmt.Errorf("can't forward %s message", getTypeFromMessage(original))
+	}
+	mentions, score := ctxInfo.GetMentionedJID(), ctxInfo.GetForwardingScore()
+	proto.Reset(ctxInfo)
+	ctxInfo.MentionedJID = mentions
+	ctxInfo.IsForwarded = proto.Bool(true)
+	ctxInfo.ForwardingScore = proto.Uint32(score + 1)
+	return msg, nil
+}
+
+// ForwardMediaReuploadAge is the age after which ForwardMessage re-uploads media instead of re-referencing
+// the existing upload. Media without a key timestamp is always re-uploaded.
+var ForwardMediaReuploadAge = 14 * 24 * time.Hour
+
+// ForwardMessage forwards the given message to another chat. See BuildForward for details.
+//
+// If the message contains media that is older than ForwardMediaReuploadAge, the media is downloaded
+// and re-uploaded with ReuploadMedia before sending.
+func (cli *Client) ForwardMessage(ctx context.Context, to types.JID, original *waE2E.Message, extra ...SendRequestExtra) (SendResponse, error) {
+	msg, err := cli.BuildForward(original)
+	if err != nil {
+		return SendResponse{}, err
+	}
+	if media := getForwardableMedia(msg); media != nil && media.GetMediaKeyTimestamp() < time.Now().Add(-ForwardMediaReuploadAge).Unix() {
+		err = cli.ReuploadMedia(ctx, msg)
+		if err != nil {
+			return SendResponse{}, err
+		}
+	}
+	return cli.SendMessage(ctx, to, msg, extra...)
+}
+
+type forwardableMedia interface {
+	DownloadableMessage
+	GetMediaKeyTimestamp() int64
+}
+
+func getForwardableMedia(msg *waE2E.Message) forwardableMedia {
+	switch {
+	case msg.ImageMessage != nil:
+		return msg.ImageMessage
+	case msg.VideoMessage != nil:
+		return msg.VideoMessage
+	case msg.AudioMessage != nil:
+		return msg.AudioMessage
+	case msg.DocumentMessage != nil:
+		return msg.DocumentMessage
+	case msg.StickerMessage != nil:
+		return msg.StickerMessage
+	default:
+		return nil
+	}
+}
+
+// ReuploadMedia downloads the media in the given message and uploads it again, replacing the media keys and
+// paths in the message in-place. This can be used to forward or resend media whose original upload has expired.
+//
+// Messages without image, video, audio, document or sticker media are left unchanged.
+func (cli *Client) ReuploadMedia(ctx context.Context, msg *waE2E.Message) error {
+	media := getForwardableMedia(msg)
+	if media == nil {
+		return nil
+	}
+	data, err := cli.Download(ctx, media)
+	if err != nil {
+		return fmt.Errorf("failed to download media for re-upload: %w", err)
+	}
+	resp, err := cli.Upload(ctx, data, GetMediaType(media))
+	if err != nil {
+		return fmt.Errorf("failed to re-upload media: %w", err)
+	}
+	ts := proto.Int64(time.Now().Unix())
+	switch m := media.(type) {
+	case *waE2E.ImageMessage:
+		m.URL, m.DirectPath, m.MediaKey, m.MediaKeyTimestamp = &resp.URL, &resp.DirectPath, resp.MediaKey, ts
+		m.FileEncSHA256, m.FileSHA256, m.FileLength = resp.FileEncSHA256, resp.FileSHA256, &resp.FileLength
+	case *waE2E.VideoMessage:
+		m.URL, m.DirectPath, m.MediaKey, m.MediaKeyTimestamp = &resp.URL, &resp.DirectPath, resp.MediaKey, ts
+		m.FileEncSHA256, m.FileSHA256, m.FileLength = resp.FileEncSHA256, resp.FileSHA256, &resp.FileLength
+	case *waE2E.AudioMessage:
+		m.URL, m.DirectPath, m.MediaKey, m.MediaKeyTimestamp = &resp.URL, &resp.DirectPath, resp.MediaKey, ts
+		m.FileEncSHA256, m.FileSHA256, m.FileLength = resp.FileEncSHA256, resp.FileSHA256, &resp.FileLength
+	case *waE2E.DocumentMessage:
+		m.URL, m.DirectPath, m.MediaKey, m.MediaKeyTimestamp = &resp.URL, &resp.DirectPath, resp.MediaKey, ts
+		m.FileEncSHA256, m.FileSHA256, m.FileLength = resp.FileEncSHA256, resp.FileSHA256, &resp.FileLength
+	case *waE2E.StickerMessage:
+		m.URL, m.DirectPath, m.MediaKey, m.MediaKeyTimestamp = &resp.URL, &resp.DirectPath, resp.MediaKey, ts
+		m.FileEncSHA256, m.FileSHA256, m.FileLength = resp.FileEncSHA256, resp.FileSHA256, &resp.FileLength
+	}
+	return nil
+}
+
+// BuildReply builds a text message that replies to the given message.
+// The built message can be sent normally using Client.SendMessage.
+//
+//	resp, err := cli.SendMessage(context.Background(), evt.Info.Chat, cli.BuildReply(&evt.Info, evt.Message, "hello"))
+//
+// To reply with other message types, use BuildReplyContext.
+func (cli *Client) BuildReply(info *types.MessageInfo, original *waE2E.Message, text string) *waE2E.Message {
+	return &waE2E.Message{
+		ExtendedTextMessage: &waE2E.ExtendedTextMessage{
+			Text:        proto.String(text),
+			ContextInfo: cli.BuildReplyContext(info, original),
+		},
+	}
+}
+
+// BuildAlbum builds an album message, which groups the following media messages into a single gallery.
+// The counts should match the number of images and videos that will be sent as part of the album.
+//
+// After sending the album message, each media message must be wrapped with BuildAlbumChild using the ID of the album message.
+// SendAlbum can be used to do all of that at once.
+func (cli *Client) BuildAlbum(imageCount, videoCount int) *waE2E.Message {
+	return &waE2E.Message{
+		AlbumMessage: &waE2E.AlbumMessage{
+			ExpectedImageCount: proto.Uint32(uint32(imageCount)),
+			ExpectedVideoCount: proto.Uint32(uint32(videoCount)),
+		},
+		MessageContextInfo: &waE2E.MessageContextInfo{
+			MessageSecret: random.Bytes(32),
+		},
+	}
+}
+
+// BuildAlbumChild wraps the given image or video message so that it's associated with the given album message.
+// The built message can be sent normally using Client.SendMessage.
+func (cli *Client) BuildAlbumChild(chat types.JID, albumID types.MessageID, media *waE2E.Message) *waE2E.Message {
+	media = proto.Clone(media).(*waE2E.Message)
+	if media.MessageContextInfo == nil {
+		media.MessageContextInfo = &waE2E.MessageContextInfo{}
+	}
+	media.MessageContextInfo.MessageAssociation = &waE2E.MessageAssociation{
+		AssociationType:  waE2E.MessageAssociation_MEDIA_ALBUM.Enum(),
+		ParentMessageKey: cli.BuildMessageKey(chat, types.EmptyJID, albumID),
+	}
+	return &waE2E.Message{
+		AssociatedChildMessage: &waE2E.FutureProofMessage{
+			Message: media,
+		},
+	}
+}
+
+// SendAlbum sends the given image and video messages as a single album.
+//
+// The returned slice contains the response for the album message first, followed by the responses for each media message.
+// If sending one of the media messages fails, the responses for the messages that were already sent are returned along with the error.
+//
+// The extra parameter is applied separately to the album message and each media message. The ID, ViewOnce, MediaHandle,
+// InlineBotJID, Peer and Meta fields only make sense for a single message and are ignored. If IdempotencyKey is set,
+// each message gets its own key derived from it (key+"#album" for the album message and key+"#1", key+"#2", etc.
+// for the media messages), so retrying SendAlbum with the same key only sends the messages that weren't sent yet.
+func (cli *Client) SendAlbum(ctx context.Context, to types.JID, media []*waE2E.Message, extra ...SendRequestExtra) ([]SendResponse, error) {
+	var imageCount, videoCount int
+	for _, msg := range media {
+		switch {
+		case msg.GetImageMessage() != nil:
+			imageCount++
+		case msg.GetVideoMessage() != nil:
+			videoCount++
+		default:
+			return nil, fmt.Errorf("albums can only contain images and videos, got %s", getTypeFromMessage(msg))
+		}
+	}
+	resps := make([]SendResponse, 0, len(media)+1)
+	albumResp, err := cli.SendMessage(ctx, to, cli.BuildAlbum(imageCount, videoCount), albumItemExtra(extra, "album"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to send album message: %w", err)
+	}
+	resps = append(resps, albumResp)
+	for i, msg := range media {
+		resp, err := cli.SendMessage(ctx, to, cli.BuildAlbumChild(to, albumResp.ID, msg), albumItemExtra(extra, strconv.Itoa(i+1)))
+		if err != nil {
+			return resps, fmt.Errorf("failed to send album item #%d: %w", i+1, err)
+		}
+		resps = append(resps, resp)
+	}
+	return resps, nil
+}
+
+// albumItemExtra builds a new SendRequestExtra for one message in an album, copying only the fields
+// that are safe to apply to every message and deriving the idempotency key using the given suffix.
+func albumItemExtra(extra []SendRequestExtra, keySuffix string) SendRequestExtra {
+	if len(extra) == 0 {
+		return SendRequestExtra{}
+	}
+	itemExtra := SendRequestExtra{
+		Timeout:                extra[0].Timeout,
+		DisappearingTimer:      extra[0].DisappearingTimer,
+		DisappearingTimerSetAt: extra[0].DisappearingTimerSetAt,
+		StatusPrivacy:          extra[0].StatusPrivacy,
+	}
+	if extra[0].IdempotencyKey != "" {
+		itemExtra.IdempotencyKey = extra[0].IdempotencyKey + "#" + keySuffix
+	}
+	return itemExtra
+}
+
 const (
 	DisappearingTimerOff     = time.Duration(0)
 	DisappearingTimer24Hours = 24 * time.Hour
@@ -818,6 +1179,72 @@ func (cli *Client) sendDM(
 	return data, nil
 }
 
+// getOrCreateContextInfo returns the ContextInfo of the main content of the given message, creating it if necessary.
+// Plain Conversation messages are converted to ExtendedTextMessages, as they can't have a ContextInfo.
+// Returns nil if the message type doesn't support ContextInfo.
+func getOrCreateContextInfo(msg *waE2E.Message) *waE2E.ContextInfo {
+	if msg.Conversation != nil {
+		msg.ExtendedTextMessage = &waE2E.ExtendedTextMessage{Text: msg.Conversation}
+		msg.Conversation = nil
+	}
+	var ctxInfo **waE2E.ContextInfo
+	switch {
+	case msg.ExtendedTextMessage != nil:
+		ctxInfo = &msg.ExtendedTextMessage.ContextInfo
+	case msg.ImageMessage != nil:
+		ctxInfo = &msg.ImageMessage.ContextInfo
+	case msg.VideoMessage != nil:
+		ctxInfo = &msg.VideoMessage.ContextInfo
+	case msg.AudioMessage != nil:
+		ctxInfo = &msg.AudioMessage.ContextInfo
+	case msg.DocumentMessage != nil:
+		ctxInfo = &msg.DocumentMessage.ContextInfo
+	case msg.StickerMessage != nil:
+		ctxInfo = &msg.StickerMessage.ContextInfo
+	case msg.LocationMessage != nil:
+		ctxInfo = &msg.LocationMessage.ContextInfo
+	case msg.LiveLocationMessage != nil:
+		ctxInfo = &msg.LiveLocationMessage.ContextInfo
+	case msg.ContactMessage != nil:
+		ctxInfo = &msg.ContactMessage.ContextInfo
+	case msg.ContactsArrayMessage != nil:
+		ctxInfo = &msg.ContactsArrayMessage.ContextInfo
+	case msg.PollCreationMessage != nil:
+		ctxInfo = &msg.PollCreationMessage.ContextInfo
+	case msg.AlbumMessage != nil:
+		ctxInfo = &msg.AlbumMessage.ContextInfo
+	case msg.AssociatedChildMessage.GetMessage() != nil:
+		return getOrCreateContextInfo(msg.AssociatedChildMessage.Message)
+	default:
+		return nil
+	}
+	if *ctxInfo == nil {
+		*ctxInfo = &waE2E.ContextInfo{}
+	}
+	return *ctxInfo
+}
+
+func wrapViewOnce(msg *waE2E.Message) (*waE2E.Message, error) {
+	msgContextInfo := msg.MessageContextInfo
+	inner := proto.Clone(msg).(*waE2E.Message)
+	inner.MessageContextInfo = nil
+	wrapped := &waE2E.Message{MessageContextInfo: msgContextInfo}
+	switch {
+	case inner.ImageMessage != nil:
+		inner.ImageMessage.ViewOnce = proto.Bool(true)
+		wrapped.ViewOnceMessageV2 = &waE2E.FutureProofMessage{Message: inner}
+	case inner.VideoMessage != nil:
+		inner.VideoMessage.ViewOnce = proto.Bool(true)
+		wrapped.ViewOnceMessageV2 = &waE2E.FutureProofMessage{Message: inner}
+	case inner.AudioMessage != nil:
+		inner.AudioMessage.ViewOnce = proto.Bool(true)
+		wrapped.ViewOnceMessageV2Extension = &waE2E.FutureProofMessage{Message: inner}
+	default:
+		return nil, fmt.Errorf("can't send %s message as view-once message", getTypeFromMessage(msg))
+	}
+	return wrapped, nil
+}
+
 func getTypeFromMessage(msg *waE2E.Message) string {
 	switch {
 	case msg.ViewOnceMessage != nil:
@@ -832,6 +1259,8 @@ func getTypeFromMessage(msg *waE2E.Message) string {
 		return getTypeFromMessage(msg.EphemeralMessage.Message)
 	case msg.DocumentWithCaptionMessage != nil:
 		return getTypeFromMessage(msg.DocumentWithCaptionMessage.Message)
+	case msg.AssociatedChildMessage != nil:
+		return getTypeFromMessage(msg.AssociatedChildMessage.Message)
 	case msg.ReactionMessage != nil, msg.EncReactionMessage != nil:
 		return "reaction"
 	case msg.PollCreationMessage != nil, msg.PollUpdateMessage != nil:
@@ -859,6 +1288,8 @@ func getMediaTypeFromMessage(msg *waE2E.Message) string {
 		return getMediaTypeFromMessage(msg.EphemeralMessage.Message)
 	case msg.DocumentWithCaptionMessage != nil:
 		return getMediaTypeFromMessage(msg.DocumentWithCaptionMessage.Message)
+	case msg.AssociatedChildMessage != nil:
+		return getMediaTypeFromMessage(msg.AssociatedChildMessage.Message)
 	case msg.ExtendedTextMessage != nil && msg.ExtendedTextMessage.Title != nil:
 		return "url"
 	case msg.ImageMessage != nil:
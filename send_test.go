@@ -0,0 +1,82 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+)
+
+func TestAlbumItemExtra(t *testing.T) {
+	privacy := &types.StatusPrivacy{Type: types.StatusPrivacyTypeContacts}
+	setAt := time.Unix(1700000000, 0)
+	extra := []SendRequestExtra{{
+		ID:                     "3EB0ALBUM",
+		IdempotencyKey:         "album-key",
+		DisappearingTimer:      24 * time.Hour,
+		DisappearingTimerSetAt: setAt,
+		ViewOnce:               true,
+		MediaHandle:            "handle",
+		Peer:                   true,
+		Timeout:                10 * time.Second,
+		StatusPrivacy:          privacy,
+	}}
+	itemExtra := albumItemExtra(extra, "1")
+	if itemExtra.ID != "" {
+		t.Errorf("ID should not be copied to album items, got %q", itemExtra.ID)
+	}
+	if itemExtra.ViewOnce || itemExtra.MediaHandle != "" || itemExtra.Peer {
+		t.Errorf("Message-specific fields should not be copied to album items: %+v", itemExtra)
+	}
+	if itemExtra.Timeout != extra[0].Timeout || itemExtra.StatusPrivacy != privacy {
+		t.Errorf("Shared fields weren't copied to album items: %+v", itemExtra)
+	}
+	if itemExtra.DisappearingTimer != 24*time.Hour || !itemExtra.DisappearingTimerSetAt.Equal(setAt) {
+		t.Errorf("Disappearing timer wasn't copied to album items: %+v", itemExtra)
+	}
+	if empty := albumItemExtra(nil, "1"); empty != (SendRequestExtra{}) {
+		t.Errorf("Expected empty extra when none is given, got %+v", empty)
+	}
+}
+
+func TestAlbumItemExtraIdempotencyKey(t *testing.T) {
+	extra := []SendRequestExtra{{IdempotencyKey: "album-key"}}
+	keys := map[string]bool{}
+	for _, suffix := range []string{"album", "1", "2"} {
+		key := albumItemExtra(extra, suffix).IdempotencyKey
+		if key != "album-key#"+suffix {
+			t.Errorf("Unexpected idempotency key for %s: %q", suffix, key)
+		}
+		keys[key] = true
+	}
+	if len(keys) != 3 {
+		t.Errorf("Album messages didn't get distinct idempotency keys: %v", keys)
+	}
+	if key := albumItemExtra([]SendRequestExtra{{}}, "1").IdempotencyKey; key != "" {
+		t.Errorf("Idempotency key should stay empty when not given, got %q", key)
+	}
+}
+
+func TestAlbumDisappearingContextInfo(t *testing.T) {
+	cli := &Client{}
+	album := cli.BuildAlbum(1, 0)
+	if ctxInfo := getOrCreateContextInfo(album); ctxInfo == nil || ctxInfo != album.AlbumMessage.ContextInfo {
+		t.Errorf("Album message context info wasn't created")
+	}
+	child := &waE2E.Message{AssociatedChildMessage: &waE2E.FutureProofMessage{Message: &waE2E.Message{
+		ImageMessage: &waE2E.ImageMessage{Caption: proto.String("hi")},
+	}}}
+	ctxInfo := getOrCreateContextInfo(child)
+	if ctxInfo == nil || ctxInfo != child.AssociatedChildMessage.Message.ImageMessage.ContextInfo {
+		t.Errorf("Album child context info wasn't created in the inner media message")
+	}
+}
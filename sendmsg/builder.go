@@ -0,0 +1,251 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package sendmsg contains builders for constructing common message types without assembling the protobuf trees manually.
+//
+//	msg := sendmsg.Text("Hello @1234567890").
+//		Mention(types.NewJID("1234567890", types.DefaultUserServer)).
+//		ReplyTo(&evt.Info, evt.Message).
+//		Build()
+//	resp, err := cli.SendMessage(ctx, evt.Info.Chat, msg)
+package sendmsg
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/proto/waCommon"
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+)
+
+// Builder contains a message that is being built. Use one of the constructor functions like Text or Image to create one.
+type Builder struct {
+	msg         *waE2E.Message
+	contextInfo *waE2E.ContextInfo
+	caption     *string
+}
+
+func newBuilder(msg *waE2E.Message) *Builder {
+	return &Builder{msg: msg}
+}
+
+// Text creates a builder for a plain text message.
+func Text(text string) *Builder {
+	return newBuilder(&waE2E.Message{
+		ExtendedTextMessage: &waE2E.ExtendedTextMessage{
+			Text: proto.String(text),
+		},
+	})
+}
+
+// Image creates a builder for an image message using the given upload response.
+// The upload should be done with whatsmeow.MediaImage.
+func Image(upload whatsmeow.UploadResponse, mimetype string, width, height uint32) *Builder {
+	return newBuilder(&waE2E.Message{
+		ImageMessage: &waE2E.ImageMessage{
+			URL:           proto.String(upload.URL),
+			DirectPath:    proto.String(upload.DirectPath),
+			MediaKey:      upload.MediaKey,
+			FileEncSHA256: upload.FileEncSHA256,
+			FileSHA256:    upload.FileSHA256,
+			FileLength:    proto.Uint64(upload.FileLength),
+			Mimetype:      proto.String(mimetype),
+			Width:         proto.Uint32(width),
+			Height:        proto.Uint32(height),
+		},
+	})
+}
+
+// Video creates a builder for a video message using the given upload response.
+// The upload should be done with whatsmeow.MediaVideo.
+func Video(upload whatsmeow.UploadResponse, mimetype string, duration time.Duration) *Builder {
+	return newBuilder(&waE2E.Message{
+		VideoMessage: &waE2E.VideoMessage{
+			URL:           proto.String(upload.URL),
+			DirectPath:    proto.String(upload.DirectPath),
+			MediaKey:      upload.MediaKey,
+			FileEncSHA256: upload.FileEncSHA256,
+			FileSHA256:    upload.FileSHA256,
+			FileLength:    proto.Uint64(upload.FileLength),
+			Mimetype:      proto.String(mimetype),
+			Seconds:       proto.Uint32(uint32(duration.Seconds())),
+		},
+	})
+}
+
+// Audio creates a builder for an audio message using the given upload response.
+// The upload should be done with whatsmeow.MediaAudio. Set ptt to true to send a voice message.
+func Audio(upload whatsmeow.UploadResponse, mimetype string, duration time.Duration, ptt bool) *Builder {
+	return newBuilder(&waE2E.Message{
+		AudioMessage: &waE2E.AudioMessage{
+			URL:           proto.String(upload.URL),
+			DirectPath:    proto.String(upload.DirectPath),
+			MediaKey:      upload.MediaKey,
+			FileEncSHA256: upload.FileEncSHA256,
+			FileSHA256:    upload.FileSHA256,
+			FileLength:    proto.Uint64(upload.FileLength),
+			Mimetype:      proto.String(mimetype),
+			Seconds:       proto.Uint32(uint32(duration.Seconds())),
+			PTT:           proto.Bool(ptt),
+		},
+	})
+}
+
+// Document creates a builder for a document message using the given upload response.
+// The upload should be done with whatsmeow.MediaDocument.
+func Document(upload whatsmeow.UploadResponse, mimetype, fileName string) *Builder {
+	return newBuilder(&waE2E.Message{
+		DocumentMessage: &waE2E.DocumentMessage{
+			URL:           proto.String(upload.URL),
+			DirectPath:    proto.String(upload.DirectPath),
+			MediaKey:      upload.MediaKey,
+			FileEncSHA256: upload.FileEncSHA256,
+			FileSHA256:    upload.FileSHA256,
+			FileLength:    proto.Uint64(upload.FileLength),
+			Mimetype:      proto.String(mimetype),
+			FileName:      proto.String(fileName),
+			Title:         proto.String(fileName),
+		},
+	})
+}
+
+// Sticker creates a builder for a sticker message using the given upload response.
+// The upload should be done with whatsmeow.MediaImage and the sticker should be a WebP image.
+func Sticker(upload whatsmeow.UploadResponse, width, height uint32) *Builder {
+	return newBuilder(&waE2E.Message{
+		StickerMessage: &waE2E.StickerMessage{
+			URL:           proto.String(upload.URL),
+			DirectPath:    proto.String(upload.DirectPath),
+			MediaKey:      upload.MediaKey,
+			FileEncSHA256: upload.FileEncSHA256,
+			FileSHA256:    upload.FileSHA256,
+			FileLength:    proto.Uint64(upload.FileLength),
+			Mimetype:      proto.String("image/webp"),
+			Width:         proto.Uint32(width),
+			Height:        proto.Uint32(height),
+		},
+	})
+}
+
+// Location creates a builder for a static location message. The name and address are optional.
+func Location(latitude, longitude float64, name, address string) *Builder {
+	loc := &waE2E.LocationMessage{
+		DegreesLatitude:  proto.Float64(latitude),
+		DegreesLongitude: proto.Float64(longitude),
+	}
+	if name != "" {
+		loc.Name = proto.String(name)
+	}
+	if address != "" {
+		loc.Address = proto.String(address)
+	}
+	return newBuilder(&waE2E.Message{LocationMessage: loc})
+}
+
+// Contact creates a builder for a contact card message. The vcard parameter must be a full vCard string.
+func Contact(displayName, vcard string) *Builder {
+	return newBuilder(&waE2E.Message{
+		ContactMessage: &waE2E.ContactMessage{
+			DisplayName: proto.String(displayName),
+			Vcard:       proto.String(vcard),
+		},
+	})
+}
+
+// Reaction creates a builder for a reaction message. An empty reaction removes the previous reaction.
+//
+// The key can be built using Client.BuildMessageKey. Reactions don't have context info,
+// so any ReplyTo, Mention or Ephemeral calls are ignored for them.
+func Reaction(key *waCommon.MessageKey, reaction string) *Builder {
+	return newBuilder(&waE2E.Message{
+		ReactionMessage: &waE2E.ReactionMessage{
+			Key:               key,
+			Text:              proto.String(reaction),
+			SenderTimestampMS: proto.Int64(time.Now().UnixMilli()),
+		},
+	})
+}
+
+func (b *Builder) getContextInfo() *waE2E.ContextInfo {
+	if b.contextInfo == nil {
+		b.contextInfo = &waE2E.ContextInfo{}
+	}
+	return b.contextInfo
+}
+
+// Caption sets the caption of a media message. It's ignored for message types that don't support captions.
+func (b *Builder) Caption(caption string) *Builder {
+	b.caption = proto.String(caption)
+	return b
+}
+
+// ReplyTo makes the message quote the given message.
+// The info and message should be the ones from the *events.Message that is being replied to.
+func (b *Builder) ReplyTo(info *types.MessageInfo, original *waE2E.Message) *Builder {
+	reply := whatsmeow.NewReplyContextInfo(info, original)
+	ci := b.getContextInfo()
+	ci.StanzaID = reply.StanzaID
+	ci.Participant = reply.Participant
+	ci.QuotedMessage = reply.QuotedMessage
+	ci.RemoteJID = reply.RemoteJID
+	return b
+}
+
+// Mention adds the given users to the list of mentioned users.
+//
+// The message text should contain @<user> for each mentioned user, see also whatsmeow.ParseMentions.
+func (b *Builder) Mention(users ...types.JID) *Builder {
+	ci := b.getContextInfo()
+	for _, user := range users {
+		ci.MentionedJID = append(ci.MentionedJID, user.ToNonAD().String())
+	}
+	return b
+}
+
+// Ephemeral marks the message as a disappearing message with the given timer.
+// The timer should match the chat's disappearing timer, otherwise the message may not disappear in other clients.
+func (b *Builder) Ephemeral(timer time.Duration) *Builder {
+	if timer > 0 {
+		b.getContextInfo().Expiration = proto.Uint32(uint32(timer.Seconds()))
+	}
+	return b
+}
+
+// ContextInfo returns the context info of the message that is being built, creating it if necessary.
+// This can be used to set fields that don't have a dedicated builder method.
+func (b *Builder) ContextInfo() *waE2E.ContextInfo {
+	return b.getContextInfo()
+}
+
+// Build returns the built message, which can be sent using Client.SendMessage.
+func (b *Builder) Build() *waE2E.Message {
+	msg := b.msg
+	switch {
+	case msg.ExtendedTextMessage != nil:
+		msg.ExtendedTextMessage.ContextInfo = b.contextInfo
+	case msg.ImageMessage != nil:
+		msg.ImageMessage.Caption = b.caption
+		msg.ImageMessage.ContextInfo = b.contextInfo
+	case msg.VideoMessage != nil:
+		msg.VideoMessage.Caption = b.caption
+		msg.VideoMessage.ContextInfo = b.contextInfo
+	case msg.AudioMessage != nil:
+		msg.AudioMessage.ContextInfo = b.contextInfo
+	case msg.DocumentMessage != nil:
+		msg.DocumentMessage.Caption = b.caption
+		msg.DocumentMessage.ContextInfo = b.contextInfo
+	case msg.StickerMessage != nil:
+		msg.StickerMessage.ContextInfo = b.contextInfo
+	case msg.LocationMessage != nil:
+		msg.LocationMessage.ContextInfo = b.contextInfo
+	case msg.ContactMessage != nil:
+		msg.ContactMessage.ContextInfo = b.contextInfo
+	}
+	return msg
+}
@@ -0,0 +1,98 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package sendmsg
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"net/http"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+)
+
+// ThumbnailSize is the maximum width and height of thumbnails generated by UploadImage.
+const ThumbnailSize = 72
+
+// UploadImage uploads the given image and returns a builder for an image message with the mimetype,
+// dimensions, file length and thumbnail filled automatically.
+//
+// Only JPEG, PNG and GIF images can be decoded for the dimensions and thumbnail.
+// Other formats are still uploaded, but the dimensions and thumbnail will be left empty.
+func UploadImage(ctx context.Context, cli *whatsmeow.Client, data []byte) (*Builder, error) {
+	upload, err := cli.Upload(ctx, data, whatsmeow.MediaImage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload image: %w", err)
+	}
+	var width, height uint32
+	var thumbnail []byte
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err == nil {
+		bounds := img.Bounds()
+		width, height = uint32(bounds.Dx()), uint32(bounds.Dy())
+		thumbnail, err = makeThumbnail(img)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate thumbnail: %w", err)
+		}
+	}
+	b := Image(upload, http.DetectContentType(data), width, height)
+	b.msg.ImageMessage.JPEGThumbnail = thumbnail
+	return b, nil
+}
+
+// UploadVideo uploads the given video and returns a builder for a video message with the mimetype and file length
+// filled automatically. The duration can't be detected without decoding the video, so it must be provided by the caller.
+func UploadVideo(ctx context.Context, cli *whatsmeow.Client, data []byte, duration time.Duration) (*Builder, error) {
+	upload, err := cli.Upload(ctx, data, whatsmeow.MediaVideo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload video: %w", err)
+	}
+	return Video(upload, http.DetectContentType(data), duration), nil
+}
+
+// UploadDocument uploads the given file and returns a builder for a document message with the mimetype
+// and file length filled automatically.
+func UploadDocument(ctx context.Context, cli *whatsmeow.Client, data []byte, fileName string) (*Builder, error) {
+	upload, err := cli.Upload(ctx, data, whatsmeow.MediaDocument)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload document: %w", err)
+	}
+	return Document(upload, http.DetectContentType(data), fileName), nil
+}
+
+func makeThumbnail(img image.Image) ([]byte, error) {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > ThumbnailSize || height > ThumbnailSize {
+		if width > height {
+			height = max(height*ThumbnailSize/width, 1)
+			width = ThumbnailSize
+		} else {
+			width = max(width*ThumbnailSize/height, 1)
+			height = ThumbnailSize
+		}
+	}
+	// Simple nearest-neighbor scaling is good enough for tiny previews
+	thumb := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		srcY := bounds.Min.Y + y*bounds.Dy()/height
+		for x := 0; x < width; x++ {
+			thumb.Set(x, y, img.At(bounds.Min.X+x*bounds.Dx()/width, srcY))
+		}
+	}
+	var buf bytes.Buffer
+	err := jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: 75})
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package sendmsg
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"go.mau.fi/whatsmeow"
+)
+
+// WaveformLength is the number of samples in a voice message waveform.
+const WaveformLength = 64
+
+// PCMDecoder decodes an audio file into mono 16-bit PCM samples.
+//
+// Go doesn't have a built-in opus decoder, so the caller must provide one (e.g. using ffmpeg or an opus library).
+type PCMDecoder func(data []byte) (samples []int16, sampleRate int, err error)
+
+// ComputeWaveform computes the waveform of a voice message from mono PCM samples.
+// The returned slice is WaveformLength bytes long, and each value is between 0 and 100.
+func ComputeWaveform(samples []int16) []byte {
+	waveform := make([]byte, WaveformLength)
+	if len(samples) == 0 {
+		return waveform
+	}
+	averages := make([]float64, WaveformLength)
+	var maxAverage float64
+	for i := range averages {
+		start := i * len(samples) / WaveformLength
+		end := (i + 1) * len(samples) / WaveformLength
+		if end <= start {
+			end = min(start+1, len(samples))
+		}
+		var sum float64
+		for _, sample := range samples[start:end] {
+			if sample < 0 {
+				sum -= float64(sample)
+			} else {
+				sum += float64(sample)
+			}
+		}
+		averages[i] = sum / float64(end-start)
+		maxAverage = max(maxAverage, averages[i])
+	}
+	if maxAverage == 0 {
+		return waveform
+	}
+	for i, avg := range averages {
+		waveform[i] = byte(avg / maxAverage * 100)
+	}
+	return waveform
+}
+
+// UploadVoiceNote uploads the given audio file and returns a builder for a voice message (PTT)
+// with the waveform and duration computed using the given decoder.
+//
+// Voice messages should be Ogg Opus files with the mimetype "audio/ogg; codecs=opus".
+func UploadVoiceNote(ctx context.Context, cli *whatsmeow.Client, data []byte, mimetype string, decode PCMDecoder) (*Builder, error) {
+	samples, sampleRate, err := decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode audio: %w", err)
+	} else if sampleRate <= 0 {
+		return nil, fmt.Errorf("invalid sample rate %d", sampleRate)
+	}
+	upload, err := cli.Upload(ctx, data, whatsmeow.MediaAudio)
+	if err != nil {
+		return nil, fmt.Errorf("failed to upload voice message: %w", err)
+	}
+	duration := time.Duration(len(samples)) * time.Second / time.Duration(sampleRate)
+	b := Audio(upload, mimetype, duration, true)
+	b.msg.AudioMessage.Waveform = ComputeWaveform(samples)
+	if b.msg.AudioMessage.GetSeconds() == 0 && duration > 0 {
+		b.msg.AudioMessage.Seconds = proto.Uint32(1)
+	}
+	return b, nil
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package whatsmeow
+
+import (
+	"maps"
+	"sync"
+	"time"
+)
+
+// Statistics contains traffic counters for a client. See Client.GetStatistics.
+//
+// Byte counts are the sizes of the decrypted frames, i.e. they don't include the websocket and
+// noise encryption overhead. Frames sent during the handshake are not counted.
+type Statistics struct {
+	// The time when the counters were last reset (or when the client was created).
+	Since time.Time
+
+	FramesSent     uint64
+	FramesReceived uint64
+	BytesSent      uint64
+	BytesReceived  uint64
+
+	// The number of nodes sent and received, grouped by the node tag (e.g. message, receipt, iq).
+	NodesSent     map[string]uint64
+	NodesReceived map[string]uint64
+}
+
+type trafficStats struct {
+	lock sync.Mutex
+	data Statistics
+}
+
+func (ts *trafficStats) reset() {
+	ts.lock.Lock()
+	ts.data = Statistics{
+		Since:         time.Now(),
+		NodesSent:     make(map[string]uint64),
+		NodesReceived: make(map[string]uint64),
+	}
+	ts.lock.Unlock()
+}
+
+func (ts *trafficStats) recordSent(tag string, size int) {
+	ts.lock.Lock()
+	ts.data.FramesSent++
+	ts.data.BytesSent += uint64(size)
+	if tag != "" {
+		if ts.data.NodesSent == nil {
+			ts.data.NodesSent = make(map[string]uint64)
+		}
+		ts.data.NodesSent[tag]++
+	}
+	ts.lock.Unlock()
+}
+
+func (ts *trafficStats) recordReceivedFrame(size int) {
+	ts.lock.Lock()
+	ts.data.FramesReceived++
+	ts.data.BytesReceived += uint64(size)
+	ts.lock.Unlock()
+}
+
+func (ts *trafficStats) recordReceivedNode(tag string) {
+	ts.lock.Lock()
+	if ts.data.NodesReceived == nil {
+		ts.data.NodesReceived = make(map[string]uint64)
+	}
+	ts.data.NodesReceived[tag]++
+	ts.lock.Unlock()
+}
+
+// GetStatistics returns a snapshot of the traffic counters of this client.
+// The counters are kept across reconnects until ResetStatistics is called.
+func (cli *Client) GetStatistics() Statistics {
+	cli.stats.lock.Lock()
+	defer cli.stats.lock.Unlock()
+	snapshot := cli.stats.data
+	snapshot.NodesSent = maps.Clone(snapshot.NodesSent)
+	snapshot.NodesReceived = maps.Clone(snapshot.NodesReceived)
+	return snapshot
+}
+
+// ResetStatistics resets all traffic counters of this client to zero.
+func (cli *Client) ResetStatistics() {
+	cli.stats.reset()
+}
@@ -100,6 +100,16 @@ func SetWAVersion(version WAVersionContainer) {
 	}
 	waVersion = version
 	waVersionHash = version.Hash()
+	BaseClientPayload.UserAgent.AppVersion = waVersion.ProtoAppVersion()
+}
+
+// GetWAVersion returns the web client version used by this device,
+// which is either Device.WAVersion or the global version if the former is not set.
+func (device *Device) GetWAVersion() WAVersionContainer {
+	if device.WAVersion.IsZero() {
+		return waVersion
+	}
+	return device.WAVersion
 }
 
 var BaseClientPayload = &waWa6.ClientPayload{
@@ -135,6 +145,32 @@ var DeviceProps = &waCompanionReg.DeviceProps{
 	RequireFullSync: proto.Bool(false),
 }
 
+// NewDeviceProps creates a new DeviceProps struct for use in Device.DeviceProps.
+//
+// The name is shown in the linked devices list on the phone. The platform type determines the icon.
+func NewDeviceProps(name string, platform waCompanionReg.DeviceProps_PlatformType, version [3]uint32) *waCompanionReg.DeviceProps {
+	return &waCompanionReg.DeviceProps{
+		Os: proto.String(name),
+		Version: &waCompanionReg.DeviceProps_AppVersion{
+			Primary:   proto.Uint32(version[0]),
+			Secondary: proto.Uint32(version[1]),
+			Tertiary:  proto.Uint32(version[2]),
+		},
+		PlatformType:      platform.Enum(),
+		RequireFullSync:   proto.Bool(DeviceProps.GetRequireFullSync()),
+		HistorySyncConfig: DeviceProps.GetHistorySyncConfig(),
+	}
+}
+
+// GetDeviceProps returns the device props that will be sent when pairing this device,
+// which is either Device.DeviceProps or the global DeviceProps if the former is not set.
+func (device *Device) GetDeviceProps() *waCompanionReg.DeviceProps {
+	if device.DeviceProps != nil {
+		return device.DeviceProps
+	}
+	return DeviceProps
+}
+
 func SetOSInfo(name string, version [3]uint32) {
 	DeviceProps.Os = &name
 	DeviceProps.Version.Primary = &version[0]
@@ -144,13 +180,25 @@ func SetOSInfo(name string, version [3]uint32) {
 	BaseClientPayload.UserAgent.OsBuildNumber = BaseClientPayload.UserAgent.OsVersion
 }
 
-func (device *Device) getRegistrationPayload() *waWa6.ClientPayload {
+func (device *Device) getBasePayload() *waWa6.ClientPayload {
 	payload := proto.Clone(BaseClientPayload).(*waWa6.ClientPayload)
+	if !device.WAVersion.IsZero() {
+		payload.UserAgent.AppVersion = device.WAVersion.ProtoAppVersion()
+	}
+	return payload
+}
+
+func (device *Device) getRegistrationPayload() *waWa6.ClientPayload {
+	payload := device.getBasePayload()
 	regID := make([]byte, 4)
 	binary.BigEndian.PutUint32(regID, device.RegistrationID)
 	preKeyID := make([]byte, 4)
 	binary.BigEndian.PutUint32(preKeyID, device.SignedPreKey.KeyID)
-	deviceProps, _ := proto.Marshal(DeviceProps)
+	deviceProps, _ := proto.Marshal(device.GetDeviceProps())
+	buildHash := waVersionHash
+	if !device.WAVersion.IsZero() {
+		buildHash = device.WAVersion.Hash()
+	}
 	payload.DevicePairingData = &waWa6.ClientPayload_DevicePairingRegistrationData{
 		ERegid:      regID,
 		EKeytype:    []byte{ecc.DjbType},
@@ -158,7 +206,7 @@ func (device *Device) getRegistrationPayload() *waWa6.ClientPayload {
 		ESkeyID:     preKeyID[1:],
 		ESkeyVal:    device.SignedPreKey.Pub[:],
 		ESkeySig:    device.SignedPreKey.Signature[:],
-		BuildHash:   waVersionHash[:],
+		BuildHash:   buildHash[:],
 		DeviceProps: deviceProps,
 	}
 	payload.Passive = proto.Bool(false)
@@ -167,7 +215,7 @@ func (device *Device) getRegistrationPayload() *waWa6.ClientPayload {
 }
 
 func (device *Device) getLoginPayload() *waWa6.ClientPayload {
-	payload := proto.Clone(BaseClientPayload).(*waWa6.ClientPayload)
+	payload := device.getBasePayload()
 	payload.Username = proto.Uint64(device.ID.UserInt())
 	payload.Device = proto.Uint32(uint32(device.ID.Device))
 	payload.Passive = proto.Bool(true)
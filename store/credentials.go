@@ -0,0 +1,202 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package store
+
+import (
+	"bytes"
+	"crypto/pbkdf2"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.mau.fi/util/random"
+	"google.golang.org/protobuf/proto"
+
+	"go.mau.fi/whatsmeow/proto/waAdv"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/util/gcmutil"
+	"go.mau.fi/whatsmeow/util/keys"
+)
+
+var (
+	ErrInvalidCredentialBlob  = errors.New("invalid credential blob")
+	ErrCredentialDecryptFail  = errors.New("failed to decrypt credentials (wrong passphrase?)")
+	ErrNoCredentialsToExport  = errors.New("device is not logged in")
+	ErrUnsupportedCredentials = errors.New("unsupported credential blob version")
+
+	ErrExternalKeyNotExportable = errors.New("can't export credentials that use an external noise key")
+)
+
+var credentialBlobMagic = []byte("whatsmeow-credentials\x00")
+
+const (
+	credentialBlobVersion    = 1
+	credentialSaltLength     = 16
+	credentialIVLength       = 12
+	credentialKDFIterations  = 600_000
+	credentialEncryptionSize = 32
+)
+
+type exportedCredentials struct {
+	Version int `json:"version"`
+
+	ID  types.JID `json:"id"`
+	LID types.JID `json:"lid"`
+
+	RegistrationID    uint32 `json:"registration_id"`
+	NoiseKey          []byte `json:"noise_key"`
+	IdentityKey       []byte `json:"identity_key"`
+	SignedPreKey      []byte `json:"signed_pre_key"`
+	SignedPreKeyID    uint32 `json:"signed_pre_key_id"`
+	SignedPreKeySig   []byte `json:"signed_pre_key_sig"`
+	AdvSecretKey      []byte `json:"adv_secret_key"`
+	Account           []byte `json:"account"`
+	Platform          string `json:"platform"`
+	BusinessName      string `json:"business_name"`
+	PushName          string `json:"push_name"`
+	FacebookUUID      string `json:"facebook_uuid,omitempty"`
+	LIDMigrationTS    int64  `json:"lid_migration_ts,omitempty"`
+	DisappearingTimer int64  `json:"default_disappearing_timer,omitempty"`
+}
+
+func deriveCredentialKey(passphrase, salt []byte) ([]byte, error) {
+	return pbkdf2.Key(sha256.New, string(passphrase), salt, credentialKDFIterations, credentialEncryptionSize)
+}
+
+// ExportCredentials serializes the long-term keys, registration info and account state of the device
+// into a blob encrypted with the given passphrase. The blob can be loaded with ImportCredentials.
+//
+// Signal sessions, app state and other data in the device's stores are not included,
+// so other devices will have to re-establish encryption sessions after the import.
+// The exported session must not be used in two places at the same time.
+func (device *Device) ExportCredentials(passphrase []byte) ([]byte, error) {
+	if device.ID == nil || device.Account == nil {
+		return nil, ErrNoCredentialsToExport
+	} else if device.ExternalNoiseKey != nil || device.NoiseKey == nil {
+		return nil, ErrExternalKeyNotExportable
+	}
+	account, err := proto.Marshal(device.Account)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal account info: %w", err)
+	}
+	creds := exportedCredentials{
+		Version:           credentialBlobVersion,
+		ID:                *device.ID,
+		LID:               device.LID,
+		RegistrationID:    device.RegistrationID,
+		NoiseKey:          device.NoiseKey.Priv[:],
+		IdentityKey:       device.IdentityKey.Priv[:],
+		SignedPreKey:      device.SignedPreKey.Priv[:],
+		SignedPreKeyID:    device.SignedPreKey.KeyID,
+		SignedPreKeySig:   device.SignedPreKey.Signature[:],
+		AdvSecretKey:      device.AdvSecretKey,
+		Account:           account,
+		Platform:          device.Platform,
+		BusinessName:      device.BusinessName,
+		PushName:          device.PushName,
+		LIDMigrationTS:    device.LIDMigrationTimestamp,
+		DisappearingTimer: int64(device.DefaultDisappearingTimer.Seconds()),
+	}
+	if device.FacebookUUID != uuid.Nil {
+		creds.FacebookUUID = device.FacebookUUID.String()
+	}
+	plaintext, err := json.Marshal(&creds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal credentials: %w", err)
+	}
+	salt := random.Bytes(credentialSaltLength)
+	iv := random.Bytes(credentialIVLength)
+	key, err := deriveCredentialKey(passphrase, salt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive encryption key: %w", err)
+	}
+	ciphertext, err := gcmutil.Encrypt(key, iv, plaintext, credentialBlobMagic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt credentials: %w", err)
+	}
+	blob := make([]byte, 0, len(credentialBlobMagic)+len(salt)+len(iv)+len(ciphertext))
+	blob = append(blob, credentialBlobMagic...)
+	blob = append(blob, salt...)
+	blob = append(blob, iv...)
+	blob = append(blob, ciphertext...)
+	return blob, nil
+}
+
+func privateKeyFromBytes(data []byte) (*keys.KeyPair, error) {
+	if len(data) != 32 {
+		return nil, fmt.Errorf("%w: private key has invalid length %d", ErrInvalidCredentialBlob, len(data))
+	}
+	return keys.NewKeyPairFromPrivateKey([32]byte(data)), nil
+}
+
+// ImportCredentials decrypts a blob created by ExportCredentials.
+//
+// The returned device isn't attached to any container. Use a container-specific method
+// (e.g. sqlstore.Container.ImportDevice) to store it in a database.
+func ImportCredentials(blob, passphrase []byte) (*Device, error) {
+	headerLength := len(credentialBlobMagic) + credentialSaltLength + credentialIVLength
+	if len(blob) < headerLength || !bytes.HasPrefix(blob, credentialBlobMagic) {
+		return nil, ErrInvalidCredentialBlob
+	}
+	salt := blob[len(credentialBlobMagic) : len(credentialBlobMagic)+credentialSaltLength]
+	iv := blob[len(credentialBlobMagic)+credentialSaltLength : headerLength]
+	key, err := deriveCredentialKey(passphrase, salt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive encryption key: %w", err)
+	}
+	plaintext, err := gcmutil.Decrypt(key, iv, blob[headerLength:], credentialBlobMagic)
+	if err != nil {
+		return nil, ErrCredentialDecryptFail
+	}
+	var creds exportedCredentials
+	err = json.Unmarshal(plaintext, &creds)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCredentialBlob, err)
+	} else if creds.Version != credentialBlobVersion {
+		return nil, fmt.Errorf("%w %d", ErrUnsupportedCredentials, creds.Version)
+	} else if len(creds.SignedPreKeySig) != 64 {
+		return nil, fmt.Errorf("%w: signed prekey signature has invalid length %d", ErrInvalidCredentialBlob, len(creds.SignedPreKeySig))
+	}
+	device := &Device{
+		ID:                       &creds.ID,
+		LID:                      creds.LID,
+		RegistrationID:           creds.RegistrationID,
+		AdvSecretKey:             creds.AdvSecretKey,
+		Account:                  &waAdv.ADVSignedDeviceIdentity{},
+		Platform:                 creds.Platform,
+		BusinessName:             creds.BusinessName,
+		PushName:                 creds.PushName,
+		LIDMigrationTimestamp:    creds.LIDMigrationTS,
+		DefaultDisappearingTimer: time.Duration(creds.DisappearingTimer) * time.Second,
+	}
+	if device.NoiseKey, err = privateKeyFromBytes(creds.NoiseKey); err != nil {
+		return nil, err
+	} else if device.IdentityKey, err = privateKeyFromBytes(creds.IdentityKey); err != nil {
+		return nil, err
+	}
+	signedPreKey, err := privateKeyFromBytes(creds.SignedPreKey)
+	if err != nil {
+		return nil, err
+	}
+	device.SignedPreKey = &keys.PreKey{
+		KeyPair:   *signedPreKey,
+		KeyID:     creds.SignedPreKeyID,
+		Signature: (*[64]byte)(creds.SignedPreKeySig),
+	}
+	if err = proto.Unmarshal(creds.Account, device.Account); err != nil {
+		return nil, fmt.Errorf("%w: failed to unmarshal account info: %w", ErrInvalidCredentialBlob, err)
+	}
+	if creds.FacebookUUID != "" {
+		if device.FacebookUUID, err = uuid.Parse(creds.FacebookUUID); err != nil {
+			return nil, fmt.Errorf("%w: invalid facebook UUID: %w", ErrInvalidCredentialBlob, err)
+		}
+	}
+	return device, nil
+}
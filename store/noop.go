@@ -37,6 +37,8 @@ var NoopDevice = &Device{
 	MsgSecrets:    nilStore,
 	PrivacyTokens: nilStore,
 	EventBuffer:   nilStore,
+	Outbox:        nilStore,
+	Idempotency:   nilStore,
 	LIDs:          nilStore,
 	Container:     nilStore,
 }
@@ -116,6 +118,10 @@ func (n *NoopStore) GetSenderKey(ctx context.Context, group, user string) ([]byt
 	return nil, n.Error
 }
 
+func (n *NoopStore) DeleteSenderKey(ctx context.Context, group, user string) error {
+	return n.Error
+}
+
 func (n *NoopStore) PutAppStateSyncKey(ctx context.Context, id []byte, key AppStateSyncKey) error {
 	return n.Error
 }
@@ -152,6 +158,10 @@ func (n *NoopStore) GetAppStateMutationMAC(ctx context.Context, name string, ind
 	return nil, n.Error
 }
 
+func (n *NoopStore) DoAppStateTxn(ctx context.Context, fn func(context.Context) error) error {
+	return fn(ctx)
+}
+
 func (n *NoopStore) PutPushName(ctx context.Context, user types.JID, pushName string) (bool, string, error) {
 	return false, "", n.Error
 }
@@ -164,18 +174,34 @@ func (n *NoopStore) PutContactName(ctx context.Context, user types.JID, fullName
 	return n.Error
 }
 
+func (n *NoopStore) PutAllPushNames(ctx context.Context, names []PushNameEntry) error {
+	return n.Error
+}
+
 func (n *NoopStore) PutAllContactNames(ctx context.Context, contacts []ContactEntry) error {
 	return n.Error
 }
 
+func (n *NoopStore) PutAllContacts(ctx context.Context, contacts map[types.JID]types.ContactInfo) error {
+	return n.Error
+}
+
 func (n *NoopStore) GetContact(ctx context.Context, user types.JID) (types.ContactInfo, error) {
 	return types.ContactInfo{}, n.Error
 }
 
+func (n *NoopStore) GetContacts(ctx context.Context, users []types.JID) (map[types.JID]types.ContactInfo, error) {
+	return nil, n.Error
+}
+
 func (n *NoopStore) GetAllContacts(ctx context.Context) (map[types.JID]types.ContactInfo, error) {
 	return nil, n.Error
 }
 
+func (n *NoopStore) SearchContacts(ctx context.Context, query string) (map[types.JID]types.ContactInfo, error) {
+	return nil, n.Error
+}
+
 func (n *NoopStore) PutMutedUntil(ctx context.Context, chat types.JID, mutedUntil time.Time) error {
 	return n.Error
 }
@@ -240,6 +266,38 @@ func (n *NoopStore) DeleteOldBufferedHashes(ctx context.Context) error {
 	return nil
 }
 
+func (n *NoopStore) PutOutboxMessage(ctx context.Context, msg *OutboxMessage) error {
+	return n.Error
+}
+
+func (n *NoopStore) GetOutboxMessages(ctx context.Context) ([]*OutboxMessage, error) {
+	return nil, n.Error
+}
+
+func (n *NoopStore) IncrementOutboxAttempts(ctx context.Context, id types.MessageID) error {
+	return n.Error
+}
+
+func (n *NoopStore) DeleteOutboxMessage(ctx context.Context, id types.MessageID) error {
+	return n.Error
+}
+
+func (n *NoopStore) GetIdempotencyKey(ctx context.Context, key string) (*IdempotencyKey, error) {
+	return nil, n.Error
+}
+
+func (n *NoopStore) PutIdempotencyKey(ctx context.Context, key string, chat types.JID, id types.MessageID) error {
+	return n.Error
+}
+
+func (n *NoopStore) MarkIdempotencyKeySent(ctx context.Context, key string, ts time.Time) error {
+	return n.Error
+}
+
+func (n *NoopStore) DeleteOldIdempotencyKeys(ctx context.Context) error {
+	return n.Error
+}
+
 func (n *NoopStore) GetLIDForPN(ctx context.Context, pn types.JID) (types.JID, error) {
 	return types.JID{}, n.Error
 }
@@ -0,0 +1,246 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package sqlstore
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"go.mau.fi/util/dbutil"
+)
+
+// DefaultSignalCacheSize is the recommended value for Container.SignalCacheSize when enabling the in-memory Signal store caches.
+const DefaultSignalCacheSize = 10000
+
+type senderKeyCacheKey struct {
+	group string
+	user  string
+}
+
+// signalCache is a read-through cache for identity keys, sessions and sender keys.
+//
+// Values are only inserted into the cache when they're known to match the committed database state:
+// writes inside transactions just invalidate the cached entry, and reads don't populate the cache
+// while any transaction is in progress or if a write happened while the read was running.
+type signalCache struct {
+	lock       sync.Mutex
+	maxSize    int
+	generation uint64
+	activeTxns int
+
+	identities map[string]*[32]byte
+	sessions   map[string][]byte
+	senderKeys map[senderKeyCacheKey][]byte
+}
+
+func newSignalCache(maxSize int) *signalCache {
+	if maxSize <= 0 {
+		return nil
+	}
+	return &signalCache{
+		maxSize:    maxSize,
+		identities: make(map[string]*[32]byte),
+		sessions:   make(map[string][]byte),
+		senderKeys: make(map[senderKeyCacheKey][]byte),
+	}
+}
+
+func isInTxn(ctx context.Context, db *dbutil.Database) bool {
+	_, ok := db.Execable(ctx).(*dbutil.LoggingTxn)
+	return ok
+}
+
+func putBounded[K comparable, V any](sc *signalCache, m map[K]V, key K, value V) {
+	if _, exists := m[key]; !exists && len(m) >= sc.maxSize {
+		clear(m)
+	}
+	m[key] = value
+}
+
+// startRead returns the current generation, which must be passed to canPopulate after the database read.
+func (sc *signalCache) startRead() uint64 {
+	if sc == nil {
+		return 0
+	}
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+	return sc.generation
+}
+
+// canPopulate checks whether a value read from the database can be stored in the cache.
+// The lock must be held when calling this.
+func (sc *signalCache) canPopulate(ctx context.Context, db *dbutil.Database, generation uint64) bool {
+	return sc.activeTxns == 0 && sc.generation == generation && !isInTxn(ctx, db)
+}
+
+func (sc *signalCache) beginTxn() {
+	if sc == nil {
+		return
+	}
+	sc.lock.Lock()
+	sc.activeTxns++
+	sc.lock.Unlock()
+}
+
+func (sc *signalCache) endTxn(clearAll bool) {
+	if sc == nil {
+		return
+	}
+	sc.lock.Lock()
+	sc.activeTxns--
+	sc.generation++
+	if clearAll {
+		sc.clearLocked()
+	}
+	sc.lock.Unlock()
+}
+
+func (sc *signalCache) clearLocked() {
+	clear(sc.identities)
+	clear(sc.sessions)
+	clear(sc.senderKeys)
+}
+
+func (sc *signalCache) getIdentity(address string) (key *[32]byte, ok bool) {
+	if sc == nil {
+		return
+	}
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+	key, ok = sc.identities[address]
+	return
+}
+
+func (sc *signalCache) populateIdentity(ctx context.Context, db *dbutil.Database, generation uint64, address string, key *[32]byte) {
+	if sc == nil {
+		return
+	}
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+	if sc.canPopulate(ctx, db, generation) {
+		putBounded(sc, sc.identities, address, key)
+	}
+}
+
+// updateIdentity is called after an identity key is written or deleted. A nil key means the identity was deleted.
+func (sc *signalCache) updateIdentity(ctx context.Context, db *dbutil.Database, address string, key *[32]byte) {
+	if sc == nil {
+		return
+	}
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+	sc.generation++
+	if isInTxn(ctx, db) {
+		delete(sc.identities, address)
+	} else {
+		putBounded(sc, sc.identities, address, key)
+	}
+}
+
+func (sc *signalCache) getSession(address string) (session []byte, ok bool) {
+	if sc == nil {
+		return
+	}
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+	session, ok = sc.sessions[address]
+	return
+}
+
+func (sc *signalCache) populateSession(ctx context.Context, db *dbutil.Database, generation uint64, address string, session []byte) {
+	if sc == nil {
+		return
+	}
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+	if sc.canPopulate(ctx, db, generation) {
+		putBounded(sc, sc.sessions, address, session)
+	}
+}
+
+// updateSession is called after a session is written or deleted. A nil session means the session was deleted.
+func (sc *signalCache) updateSession(ctx context.Context, db *dbutil.Database, address string, session []byte) {
+	if sc == nil {
+		return
+	}
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+	sc.generation++
+	if isInTxn(ctx, db) {
+		delete(sc.sessions, address)
+	} else {
+		putBounded(sc, sc.sessions, address, session)
+	}
+}
+
+func (sc *signalCache) getSenderKey(group, user string) (key []byte, ok bool) {
+	if sc == nil {
+		return
+	}
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+	key, ok = sc.senderKeys[senderKeyCacheKey{group, user}]
+	return
+}
+
+func (sc *signalCache) populateSenderKey(ctx context.Context, db *dbutil.Database, generation uint64, group, user string, key []byte) {
+	if sc == nil {
+		return
+	}
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+	if sc.canPopulate(ctx, db, generation) {
+		putBounded(sc, sc.senderKeys, senderKeyCacheKey{group, user}, key)
+	}
+}
+
+func (sc *signalCache) updateSenderKey(ctx context.Context, db *dbutil.Database, group, user string, key []byte) {
+	if sc == nil {
+		return
+	}
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+	sc.generation++
+	if isInTxn(ctx, db) {
+		delete(sc.senderKeys, senderKeyCacheKey{group, user})
+	} else {
+		putBounded(sc, sc.senderKeys, senderKeyCacheKey{group, user}, key)
+	}
+}
+
+// invalidateUser removes all cached entries of the given Signal address user (i.e. addresses starting with `user:`).
+func (sc *signalCache) invalidateUser(user string, identities, sessions, senderKeys bool) {
+	if sc == nil {
+		return
+	}
+	prefix := user + ":"
+	sc.lock.Lock()
+	defer sc.lock.Unlock()
+	sc.generation++
+	if identities {
+		for address := range sc.identities {
+			if strings.HasPrefix(address, prefix) {
+				delete(sc.identities, address)
+			}
+		}
+	}
+	if sessions {
+		for address := range sc.sessions {
+			if strings.HasPrefix(address, prefix) {
+				delete(sc.sessions, address)
+			}
+		}
+	}
+	if senderKeys {
+		for key := range sc.senderKeys {
+			if strings.HasPrefix(key.user, prefix) {
+				delete(sc.senderKeys, key)
+			}
+		}
+	}
+}
@@ -0,0 +1,217 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package sqlstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"go.mau.fi/whatsmeow/proto/waAdv"
+	"go.mau.fi/whatsmeow/types"
+)
+
+var errRollback = errors.New("rollback")
+
+func newTestSQLStore(t *testing.T, cacheSize int) *SQLStore {
+	t.Helper()
+	ctx := context.Background()
+	addr := "file:" + filepath.Join(t.TempDir(), "whatsmeow.db") + "?_foreign_keys=on"
+	container, err := New(ctx, "sqlite3", addr, nil)
+	if err != nil {
+		t.Fatalf("failed to create container: %v", err)
+	}
+	t.Cleanup(func() { _ = container.Close() })
+	container.SignalCacheSize = cacheSize
+	device := container.NewDevice()
+	ownID := types.NewJID("1234567890", types.DefaultUserServer)
+	ownID.Device = 1
+	device.ID = &ownID
+	device.Account = &waAdv.ADVSignedDeviceIdentity{
+		Details:             []byte{},
+		AccountSignature:    make([]byte, 64),
+		AccountSignatureKey: make([]byte, 32),
+		DeviceSignature:     make([]byte, 64),
+	}
+	if err = device.Save(ctx); err != nil {
+		t.Fatalf("failed to save device: %v", err)
+	}
+	return device.Sessions.(*SQLStore)
+}
+
+func getTestSession(t *testing.T, s *SQLStore, ctx context.Context, address string) []byte {
+	t.Helper()
+	session, err := s.GetSession(ctx, address)
+	if err != nil {
+		t.Fatalf("failed to get session %s: %v", address, err)
+	}
+	return session
+}
+
+func TestSignalCacheReadThrough(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSQLStore(t, DefaultSignalCacheSize)
+	const address = "1111111111.0:1"
+
+	// Insert directly into the database, bypassing the cache
+	_, err := s.db.Exec(ctx, putSessionQuery, s.JID, address, []byte("first"))
+	if err != nil {
+		t.Fatalf("failed to insert session: %v", err)
+	}
+	if session := getTestSession(t, s, ctx, address); !bytes.Equal(session, []byte("first")) {
+		t.Fatalf("expected session from database, got %q", session)
+	}
+	// Further reads should come from the cache even if the database is changed externally
+	_, err = s.db.Exec(ctx, putSessionQuery, s.JID, address, []byte("external"))
+	if err != nil {
+		t.Fatalf("failed to update session: %v", err)
+	}
+	if session := getTestSession(t, s, ctx, address); !bytes.Equal(session, []byte("first")) {
+		t.Fatalf("expected cached session, got %q", session)
+	}
+	// Writes through the store update the cache
+	if err = s.PutSession(ctx, address, []byte("second")); err != nil {
+		t.Fatalf("failed to put session: %v", err)
+	}
+	if session := getTestSession(t, s, ctx, address); !bytes.Equal(session, []byte("second")) {
+		t.Fatalf("expected updated session, got %q", session)
+	}
+
+	// Missing entries are cached too
+	const missingAddress = "2222222222.0:1"
+	if session := getTestSession(t, s, ctx, missingAddress); session != nil {
+		t.Fatalf("expected no session, got %q", session)
+	}
+	if _, ok := s.signalCache.getSession(missingAddress); !ok {
+		t.Fatalf("expected missing session to be cached")
+	}
+	if has, err := s.HasSession(ctx, missingAddress); err != nil || has {
+		t.Fatalf("expected no session, got %t/%v", has, err)
+	}
+	if err = s.DeleteSession(ctx, address); err != nil {
+		t.Fatalf("failed to delete session: %v", err)
+	}
+	if has, err := s.HasSession(ctx, address); err != nil || has {
+		t.Fatalf("expected deleted session to be gone, got %t/%v", has, err)
+	}
+}
+
+func TestSignalCacheRolledBackTxn(t *testing.T) {
+	ctx := context.Background()
+	s := newTestSQLStore(t, DefaultSignalCacheSize)
+	const address = "1111111111.0:1"
+	const group = "123456789@g.us"
+	identity := [32]byte{1}
+	otherIdentity := [32]byte{2}
+
+	if err := s.PutSession(ctx, address, []byte("committed")); err != nil {
+		t.Fatalf("failed to put session: %v", err)
+	} else if err = s.PutIdentity(ctx, address, identity); err != nil {
+		t.Fatalf("failed to put identity: %v", err)
+	} else if err = s.PutSenderKey(ctx, group, address, []byte("committed")); err != nil {
+		t.Fatalf("failed to put sender key: %v", err)
+	}
+
+	tests := map[string]func(ctx context.Context) error{
+		"put": func(ctx context.Context) error {
+			if err := s.PutSession(ctx, address, []byte("rolled back")); err != nil {
+				return err
+			} else if err = s.PutIdentity(ctx, address, otherIdentity); err != nil {
+				return err
+			} else if err = s.PutSenderKey(ctx, group, address, []byte("rolled back")); err != nil {
+				return err
+			}
+			// Reads inside the transaction see the uncommitted values, but mustn't cache them
+			if session := getTestSession(t, s, ctx, address); !bytes.Equal(session, []byte("rolled back")) {
+				return fmt.Errorf("expected uncommitted session inside transaction, got %q", session)
+			}
+			return errRollback
+		},
+		"delete": func(ctx context.Context) error {
+			if err := s.DeleteSession(ctx, address); err != nil {
+				return err
+			} else if err = s.DeleteIdentity(ctx, address); err != nil {
+				return err
+			} else if err = s.DeleteSenderKey(ctx, group, address); err != nil {
+				return err
+			}
+			if has, err := s.HasSession(ctx, address); err != nil || has {
+				return fmt.Errorf("expected no session inside transaction, got %t/%v", has, err)
+			}
+			return errRollback
+		},
+	}
+	for name, fn := range tests {
+		txns := map[string]func(ctx context.Context, fn func(context.Context) error) error{
+			"DoTxn": func(ctx context.Context, fn func(context.Context) error) error {
+				return s.db.DoTxn(ctx, nil, fn)
+			},
+			"DoDecryptionTxn": s.DoDecryptionTxn,
+		}
+		for txnName, doTxn := range txns {
+			t.Run(name+"/"+txnName, func(t *testing.T) {
+				if err := doTxn(ctx, fn); !errors.Is(err, errRollback) {
+					t.Fatalf("expected transaction to be rolled back, got %v", err)
+				}
+				if session := getTestSession(t, s, ctx, address); !bytes.Equal(session, []byte("committed")) {
+					t.Errorf("expected committed session after rollback, got %q", session)
+				}
+				if trusted, err := s.IsTrustedIdentity(ctx, address, identity); err != nil || !trusted {
+					t.Errorf("expected committed identity after rollback, got %t/%v", trusted, err)
+				} else if trusted, err = s.IsTrustedIdentity(ctx, address, otherIdentity); err != nil || trusted {
+					t.Errorf("expected rolled back identity not to be trusted, got %t/%v", trusted, err)
+				}
+				if key, err := s.GetSenderKey(ctx, group, address); err != nil || !bytes.Equal(key, []byte("committed")) {
+					t.Errorf("expected committed sender key after rollback, got %q/%v", key, err)
+				}
+			})
+		}
+	}
+}
+
+func TestSignalCacheEviction(t *testing.T) {
+	ctx := context.Background()
+	const cacheSize = 4
+	s := newTestSQLStore(t, cacheSize)
+	for i := range cacheSize * 3 {
+		address := fmt.Sprintf("%d.0:1", 1000000+i)
+		if err := s.PutSession(ctx, address, []byte(address)); err != nil {
+			t.Fatalf("failed to put session: %v", err)
+		}
+		if size := len(s.signalCache.sessions); size > cacheSize {
+			t.Fatalf("cache has %d entries, expected at most %d", size, cacheSize)
+		}
+	}
+	// Evicted entries must still be readable from the database
+	for i := range cacheSize * 3 {
+		address := fmt.Sprintf("%d.0:1", 1000000+i)
+		if session := getTestSession(t, s, ctx, address); !bytes.Equal(session, []byte(address)) {
+			t.Errorf("expected session %s, got %q", address, session)
+		}
+		if size := len(s.signalCache.sessions); size > cacheSize {
+			t.Fatalf("cache has %d entries, expected at most %d", size, cacheSize)
+		}
+	}
+}
+
+func TestSignalCacheDisabled(t *testing.T) {
+	s := newTestSQLStore(t, 0)
+	if s.signalCache != nil {
+		t.Fatalf("expected cache to be disabled by default")
+	}
+	if err := s.PutSession(context.Background(), "1111111111.0:1", []byte("session")); err != nil {
+		t.Fatalf("failed to put session: %v", err)
+	}
+	if session := getTestSession(t, s, context.Background(), "1111111111.0:1"); !bytes.Equal(session, []byte("session")) {
+		t.Fatalf("unexpected session %q", session)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"errors"
 	"fmt"
 	mathRand "math/rand/v2"
+	"time"
 
 	"github.com/google/uuid"
 	"go.mau.fi/util/dbutil"
@@ -30,6 +31,15 @@ type Container struct {
 	db     *dbutil.Database
 	log    waLog.Logger
 	LIDMap *CachedLIDMap
+
+	// SignalCacheSize is the maximum number of identity keys, sessions and sender keys to cache in memory
+	// per device. The cache is disabled by default (zero). DefaultSignalCacheSize is a reasonable value for enabling it.
+	//
+	// The cache assumes this container is the only writer of the device's Signal data: it must not be enabled
+	// if the same device is accessed through multiple containers or processes at the same time, or if the
+	// database is modified externally, as cached values would become stale and break encryption.
+	// Changing the value only affects devices loaded afterwards.
+	SignalCacheSize int
 }
 
 var _ store.DeviceContainer = (*Container)(nil)
@@ -97,6 +107,14 @@ func NewWithWrappedDB(wrapped *dbutil.Database, log waLog.Logger) *Container {
 }
 
 // Upgrade upgrades the database from the current to the latest version available.
+//
+// On Postgres, all upgrades are done in a single transaction which holds an advisory lock,
+// so multiple processes can safely call Upgrade against the same database at the same time.
+//
+// On SQLite, each upgrade step runs in its own transaction without any cross-process lock.
+// Concurrent startups can't corrupt the database, but all except one of them may fail
+// with an error if the database needs to be upgraded, so only one process should be
+// started at a time after updating whatsmeow.
 func (c *Container) Upgrade(ctx context.Context) error {
 	if c.db.Dialect == dbutil.SQLite {
 		var foreignKeysEnabled bool
@@ -106,6 +124,8 @@ func (c *Container) Upgrade(ctx context.Context) error {
 		} else if !foreignKeysEnabled {
 			return fmt.Errorf("foreign keys are not enabled")
 		}
+	} else if c.db.Dialect == dbutil.Postgres {
+		return c.upgradeWithLock(ctx)
 	}
 
 	return c.db.Upgrade(ctx)
@@ -115,7 +135,7 @@ const getAllDevicesQuery = `
 SELECT jid, lid, registration_id, noise_key, identity_key,
        signed_pre_key, signed_pre_key_id, signed_pre_key_sig,
        adv_key, adv_details, adv_account_sig, adv_account_sig_key, adv_device_sig,
-       platform, business_name, push_name, facebook_uuid, lid_migration_ts
+       platform, business_name, push_name, facebook_uuid, lid_migration_ts, default_disappearing_timer, wa_version
 FROM whatsmeow_device
 `
 
@@ -128,24 +148,35 @@ func (c *Container) scanDevice(row dbutil.Scannable) (*store.Device, error) {
 	var noisePriv, identityPriv, preKeyPriv, preKeySig []byte
 	var account waAdv.ADVSignedDeviceIdentity
 	var fbUUID uuid.NullUUID
+	var disappearingTimer int64
+	var waVersion string
 
 	err := row.Scan(
 		&device.ID, &device.LID, &device.RegistrationID, &noisePriv, &identityPriv,
 		&preKeyPriv, &device.SignedPreKey.KeyID, &preKeySig,
 		&device.AdvSecretKey, &account.Details, &account.AccountSignature, &account.AccountSignatureKey, &account.DeviceSignature,
-		&device.Platform, &device.BusinessName, &device.PushName, &fbUUID, &device.LIDMigrationTimestamp)
+		&device.Platform, &device.BusinessName, &device.PushName, &fbUUID, &device.LIDMigrationTimestamp, &disappearingTimer, &waVersion)
 	if err != nil {
 		return nil, fmt.Errorf("failed to scan session: %w", err)
 	} else if len(noisePriv) != 32 || len(identityPriv) != 32 || len(preKeyPriv) != 32 || len(preKeySig) != 64 {
 		return nil, ErrInvalidLength
 	}
 
-	device.NoiseKey = keys.NewKeyPairFromPrivateKey(*(*[32]byte)(noisePriv))
+	if [32]byte(noisePriv) != [32]byte{} {
+		device.NoiseKey = keys.NewKeyPairFromPrivateKey(*(*[32]byte)(noisePriv))
+	}
 	device.IdentityKey = keys.NewKeyPairFromPrivateKey(*(*[32]byte)(identityPriv))
 	device.SignedPreKey.KeyPair = *keys.NewKeyPairFromPrivateKey(*(*[32]byte)(preKeyPriv))
 	device.SignedPreKey.Signature = (*[64]byte)(preKeySig)
 	device.Account = &account
 	device.FacebookUUID = fbUUID.UUID
+	device.DefaultDisappearingTimer = time.Duration(disappearingTimer) * time.Second
+	if waVersion != "" {
+		device.WAVersion, err = store.ParseVersion(waVersion)
+		if err != nil {
+			c.log.Warnf("Ignoring invalid stored web client version %q: %v", waVersion, err)
+		}
+	}
 
 	c.initializeDevice(&device)
 
@@ -202,14 +233,16 @@ const (
 		INSERT INTO whatsmeow_device (jid, lid, registration_id, noise_key, identity_key,
 									  signed_pre_key, signed_pre_key_id, signed_pre_key_sig,
 									  adv_key, adv_details, adv_account_sig, adv_account_sig_key, adv_device_sig,
-									  platform, business_name, push_name, facebook_uuid, lid_migration_ts)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+									  platform, business_name, push_name, facebook_uuid, lid_migration_ts, default_disappearing_timer, wa_version)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
 		ON CONFLICT (jid) DO UPDATE
 			SET lid=excluded.lid,
 				platform=excluded.platform,
 				business_name=excluded.business_name,
 				push_name=excluded.push_name,
-				lid_migration_ts=excluded.lid_migration_ts
+				lid_migration_ts=excluded.lid_migration_ts,
+				default_disappearing_timer=excluded.default_disappearing_timer,
+				wa_version=excluded.wa_version
 	`
 	deleteDeviceQuery = `DELETE FROM whatsmeow_device WHERE jid=$1`
 )
@@ -232,6 +265,21 @@ func (c *Container) NewDevice() *store.Device {
 	return device
 }
 
+// ImportDevice decrypts a credential blob created with store.Device.ExportCredentials and saves the device in this database.
+func (c *Container) ImportDevice(ctx context.Context, blob, passphrase []byte) (*store.Device, error) {
+	device, err := store.ImportCredentials(blob, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	device.Log = c.log
+	device.Container = c
+	err = c.PutDevice(ctx, device)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save imported device: %w", err)
+	}
+	return device, nil
+}
+
 // ErrDeviceIDMustBeSet is the error returned by PutDevice if you try to save a device before knowing its JID.
 var ErrDeviceIDMustBeSet = errors.New("device JID must be known before accessing database")
 
@@ -248,19 +296,44 @@ func (c *Container) Close() error {
 func (c *Container) PutDevice(ctx context.Context, device *store.Device) error {
 	if device.ID == nil {
 		return ErrDeviceIDMustBeSet
+	} else if device.RelinkFrom != nil {
+		return c.relinkDevice(ctx, device)
+	}
+	err := c.putDevice(ctx, device)
+	if !device.Initialized {
+		c.initializeDevice(device)
+	}
+	return err
+}
+
+func (c *Container) putDevice(ctx context.Context, device *store.Device) error {
+	var waVersion string
+	if !device.WAVersion.IsZero() {
+		waVersion = device.WAVersion.String()
 	}
-	_, err := c.db.Exec(ctx, insertDeviceQuery,
-		device.ID, device.LID, device.RegistrationID, device.NoiseKey.Priv[:], device.IdentityKey.Priv[:],
+	noisePriv, err := getStoredNoiseKey(device)
+	if err != nil {
+		return err
+	}
+	_, err = c.db.Exec(ctx, insertDeviceQuery,
+		device.ID, device.LID, device.RegistrationID, noisePriv, device.IdentityKey.Priv[:],
 		device.SignedPreKey.Priv[:], device.SignedPreKey.KeyID, device.SignedPreKey.Signature[:],
 		device.AdvSecretKey, device.Account.Details, device.Account.AccountSignature, device.Account.AccountSignatureKey, device.Account.DeviceSignature,
 		device.Platform, device.BusinessName, device.PushName, uuid.NullUUID{UUID: device.FacebookUUID, Valid: device.FacebookUUID != uuid.Nil},
-		device.LIDMigrationTimestamp,
+		device.LIDMigrationTimestamp, int64(device.DefaultDisappearingTimer.Seconds()), waVersion,
 	)
+	return err
+}
 
-	if !device.Initialized {
-		c.initializeDevice(device)
+// getStoredNoiseKey returns the noise private key to store in the database.
+// External noise keys are stored as all zeroes, see scanDevice.
+func getStoredNoiseKey(device *store.Device) ([]byte, error) {
+	if device.ExternalNoiseKey != nil {
+		return make([]byte, 32), nil
+	} else if device.NoiseKey == nil || device.NoiseKey.Priv == nil {
+		return nil, store.ErrNoNoiseKey
 	}
-	return err
+	return device.NoiseKey.Priv[:], nil
 }
 
 func (c *Container) initializeDevice(device *store.Device) {
@@ -276,6 +349,8 @@ func (c *Container) initializeDevice(device *store.Device) {
 	device.MsgSecrets = innerStore
 	device.PrivacyTokens = innerStore
 	device.EventBuffer = innerStore
+	device.Outbox = innerStore
+	device.Idempotency = innerStore
 	device.LIDs = c.LIDMap
 	device.Container = c
 	device.Initialized = true
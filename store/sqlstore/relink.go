@@ -0,0 +1,73 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package sqlstore
+
+import (
+	"context"
+	"fmt"
+
+	"go.mau.fi/whatsmeow/store"
+)
+
+// relinkDeleteQueries delete all the data that is tied to the old device's keys.
+// Everything else (contacts, chat settings, message secrets, etc.) is moved to the new device JID.
+var relinkDeleteQueries = []string{
+	`DELETE FROM whatsmeow_identity_keys WHERE our_jid=$1`,
+	`DELETE FROM whatsmeow_sessions WHERE our_jid=$1`,
+	`DELETE FROM whatsmeow_pre_keys WHERE jid=$1`,
+	`DELETE FROM whatsmeow_sender_keys WHERE our_jid=$1`,
+	`DELETE FROM whatsmeow_app_state_sync_keys WHERE jid=$1`,
+	`DELETE FROM whatsmeow_app_state_version WHERE jid=$1`,
+	`DELETE FROM whatsmeow_event_buffer WHERE our_jid=$1`,
+}
+
+// All tables referencing whatsmeow_device have ON UPDATE CASCADE, so changing the JID here moves all the data.
+const relinkDeviceQuery = `
+	UPDATE whatsmeow_device
+	SET jid=$2, registration_id=$3, noise_key=$4, identity_key=$5,
+	    signed_pre_key=$6, signed_pre_key_id=$7, signed_pre_key_sig=$8,
+	    adv_key=$9, adv_details=$10, adv_account_sig=$11, adv_account_sig_key=$12, adv_device_sig=$13
+	WHERE jid=$1
+`
+
+func (c *Container) relinkDevice(ctx context.Context, device *store.Device) error {
+	oldJID := *device.RelinkFrom
+	if oldJID.User != device.ID.User {
+		c.log.Warnf("Not moving local data from %s to %s as the account changed", oldJID, device.ID)
+		device.RelinkFrom = nil
+		return c.PutDevice(ctx, device)
+	}
+	noisePriv, err := getStoredNoiseKey(device)
+	if err != nil {
+		return err
+	}
+	err = c.db.DoTxn(ctx, nil, func(ctx context.Context) error {
+		for _, query := range relinkDeleteQueries {
+			_, err := c.db.Exec(ctx, query, oldJID)
+			if err != nil {
+				return fmt.Errorf("failed to delete old crypto data: %w", err)
+			}
+		}
+		_, err := c.db.Exec(ctx, relinkDeviceQuery,
+			oldJID, device.ID, device.RegistrationID, noisePriv, device.IdentityKey.Priv[:],
+			device.SignedPreKey.Priv[:], device.SignedPreKey.KeyID, device.SignedPreKey.Signature[:],
+			device.AdvSecretKey, device.Account.Details, device.Account.AccountSignature, device.Account.AccountSignatureKey, device.Account.DeviceSignature,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to move device row: %w", err)
+		}
+		return c.putDevice(ctx, device)
+	})
+	if err != nil {
+		return err
+	}
+	c.log.Infof("Moved local data from %s to relinked device %s", oldJID, device.ID)
+	device.RelinkFrom = nil
+	// Always reinitialize, as the existing inner stores are bound to the old JID
+	c.initializeDevice(device)
+	return nil
+}
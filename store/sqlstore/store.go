@@ -50,6 +50,8 @@ type SQLStore struct {
 	contactCacheLock sync.Mutex
 
 	migratedPNSessionsCache *exsync.Set[string]
+
+	signalCache *signalCache
 }
 
 // NewSQLStore creates a new SQLStore with the given database container and user JID.
@@ -63,6 +65,8 @@ func NewSQLStore(c *Container, jid types.JID) *SQLStore {
 		contactCache: make(map[types.JID]*types.ContactInfo),
 
 		migratedPNSessionsCache: exsync.NewSet[string](),
+
+		signalCache: newSignalCache(c.SignalCacheSize),
 	}
 }
 
@@ -80,23 +84,38 @@ const (
 
 func (s *SQLStore) PutIdentity(ctx context.Context, address string, key [32]byte) error {
 	_, err := s.db.Exec(ctx, putIdentityQuery, s.JID, address, key[:])
-	return err
+	if err != nil {
+		return err
+	}
+	s.signalCache.updateIdentity(ctx, s.db, address, &key)
+	return nil
 }
 
 func (s *SQLStore) DeleteAllIdentities(ctx context.Context, phone string) error {
 	_, err := s.db.Exec(ctx, deleteAllIdentitiesQuery, s.JID, phone+":%")
+	s.signalCache.invalidateUser(phone, true, false, false)
 	return err
 }
 
 func (s *SQLStore) DeleteIdentity(ctx context.Context, address string) error {
 	_, err := s.db.Exec(ctx, deleteAllIdentitiesQuery, s.JID, address)
-	return err
+	if err != nil {
+		return err
+	}
+	s.signalCache.updateIdentity(ctx, s.db, address, nil)
+	return nil
 }
 
 func (s *SQLStore) IsTrustedIdentity(ctx context.Context, address string, key [32]byte) (bool, error) {
+	if cached, ok := s.signalCache.getIdentity(address); ok {
+		// Trust if not known, it'll be saved automatically later
+		return cached == nil || *cached == key, nil
+	}
+	generation := s.signalCache.startRead()
 	var existingIdentity []byte
 	err := s.db.QueryRow(ctx, getIdentityQuery, s.JID, address).Scan(&existingIdentity)
 	if errors.Is(err, sql.ErrNoRows) {
+		s.signalCache.populateIdentity(ctx, s.db, generation, address, nil)
 		// Trust if not known, it'll be saved automatically later
 		return true, nil
 	} else if err != nil {
@@ -104,7 +123,9 @@ func (s *SQLStore) IsTrustedIdentity(ctx context.Context, address string, key [3
 	} else if len(existingIdentity) != 32 {
 		return false, ErrInvalidLength
 	}
-	return *(*[32]byte)(existingIdentity) == key, nil
+	existingKey := *(*[32]byte)(existingIdentity)
+	s.signalCache.populateIdentity(ctx, s.db, generation, address, &existingKey)
+	return existingKey == key, nil
 }
 
 const (
@@ -140,17 +161,28 @@ const (
 		WHERE our_jid=$1 AND sender_id LIKE $2 || ':%'
 		ON CONFLICT (our_jid, chat_id, sender_id) DO UPDATE SET sender_key=excluded.sender_key
 	`
+	deleteSenderKeyQuery = `DELETE FROM whatsmeow_sender_keys WHERE our_jid=$1 AND chat_id=$2 AND sender_id=$3`
 )
 
 func (s *SQLStore) GetSession(ctx context.Context, address string) (session []byte, err error) {
+	if cached, ok := s.signalCache.getSession(address); ok {
+		return cached, nil
+	}
+	generation := s.signalCache.startRead()
 	err = s.db.QueryRow(ctx, getSessionQuery, s.JID, address).Scan(&session)
 	if errors.Is(err, sql.ErrNoRows) {
 		err = nil
 	}
+	if err == nil {
+		s.signalCache.populateSession(ctx, s.db, generation, address, session)
+	}
 	return
 }
 
 func (s *SQLStore) HasSession(ctx context.Context, address string) (has bool, err error) {
+	if cached, ok := s.signalCache.getSession(address); ok {
+		return cached != nil, nil
+	}
 	err = s.db.QueryRow(ctx, hasSessionQuery, s.JID, address).Scan(&has)
 	if errors.Is(err, sql.ErrNoRows) {
 		err = nil
@@ -160,7 +192,11 @@ func (s *SQLStore) HasSession(ctx context.Context, address string) (has bool, er
 
 func (s *SQLStore) PutSession(ctx context.Context, address string, session []byte) error {
 	_, err := s.db.Exec(ctx, putSessionQuery, s.JID, address, session)
-	return err
+	if err != nil {
+		return err
+	}
+	s.signalCache.updateSession(ctx, s.db, address, session)
+	return nil
 }
 
 func (s *SQLStore) DeleteAllSessions(ctx context.Context, phone string) error {
@@ -169,22 +205,29 @@ func (s *SQLStore) DeleteAllSessions(ctx context.Context, phone string) error {
 
 func (s *SQLStore) deleteAllSessions(ctx context.Context, phone string) error {
 	_, err := s.db.Exec(ctx, deleteAllSessionsQuery, s.JID, phone+":%")
+	s.signalCache.invalidateUser(phone, false, true, false)
 	return err
 }
 
 func (s *SQLStore) deleteAllSenderKeys(ctx context.Context, phone string) error {
 	_, err := s.db.Exec(ctx, deleteAllSenderKeysQuery, s.JID, phone+":%")
+	s.signalCache.invalidateUser(phone, false, false, true)
 	return err
 }
 
 func (s *SQLStore) deleteAllIdentityKeys(ctx context.Context, phone string) error {
 	_, err := s.db.Exec(ctx, deleteAllIdentityKeysQuery, s.JID, phone+":%")
+	s.signalCache.invalidateUser(phone, true, false, false)
 	return err
 }
 
 func (s *SQLStore) DeleteSession(ctx context.Context, address string) error {
 	_, err := s.db.Exec(ctx, deleteSessionQuery, s.JID, address)
-	return err
+	if err != nil {
+		return err
+	}
+	s.signalCache.updateSession(ctx, s.db, address, nil)
+	return nil
 }
 
 func (s *SQLStore) MigratePNToLID(ctx context.Context, pn, lid types.JID) error {
@@ -194,6 +237,9 @@ func (s *SQLStore) MigratePNToLID(ctx context.Context, pn, lid types.JID) error
 	}
 	var sessionsUpdated, identityKeysUpdated, senderKeysUpdated int64
 	lidSignal := lid.SignalAddressUser()
+	// The migration writes LID entries directly with SQL, so just drop the whole cache afterwards
+	s.signalCache.beginTxn()
+	defer s.signalCache.endTxn(true)
 	err := s.db.DoTxn(ctx, nil, func(ctx context.Context) error {
 		res, err := s.db.Exec(ctx, migratePNToLIDSessionsQuery, s.JID, pnSignal, lidSignal)
 		if err != nil {
@@ -249,6 +295,7 @@ func (s *SQLStore) MigratePNToLID(ctx context.Context, pn, lid types.JID) error
 const (
 	getLastPreKeyIDQuery        = `SELECT MAX(key_id) FROM whatsmeow_pre_keys WHERE jid=$1`
 	insertPreKeyQuery           = `INSERT INTO whatsmeow_pre_keys (jid, key_id, key, uploaded) VALUES ($1, $2, $3, $4)`
+	putManyPreKeysQuery         = `INSERT INTO whatsmeow_pre_keys (jid, key_id, key, uploaded) VALUES `
 	getUnuploadedPreKeysQuery   = `SELECT key_id, key FROM whatsmeow_pre_keys WHERE jid=$1 AND uploaded=false ORDER BY key_id LIMIT $2`
 	getPreKeyQuery              = `SELECT key_id, key FROM whatsmeow_pre_keys WHERE jid=$1 AND key_id=$2`
 	deletePreKeyQuery           = `DELETE FROM whatsmeow_pre_keys WHERE jid=$1 AND key_id=$2`
@@ -309,17 +356,46 @@ func (s *SQLStore) GetOrGenPreKeys(ctx context.Context, count uint32) ([]*keys.P
 			return nil, err
 		}
 		for i := existingCount; i < count; i++ {
-			newKeys[i], err = s.genOnePreKey(ctx, nextKeyID, false)
-			if err != nil {
-				return nil, fmt.Errorf("failed to generate prekey: %w", err)
-			}
+			newKeys[i] = keys.NewPreKey(nextKeyID)
 			nextKeyID++
 		}
+		err = s.db.DoTxn(ctx, nil, func(ctx context.Context) error {
+			for slice := range slices.Chunk(newKeys[existingCount:], preKeyBatchSize) {
+				err := s.putPreKeysBatch(ctx, slice)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to store generated prekeys: %w", err)
+		}
 	}
 
 	return newKeys, nil
 }
 
+const preKeyBatchSize = 300
+
+func (s *SQLStore) putPreKeysBatch(ctx context.Context, preKeys []*keys.PreKey) error {
+	values := make([]any, 1+len(preKeys)*2)
+	queryParts := make([]string, len(preKeys))
+	values[0] = s.JID
+	placeholderSyntax := "($1, $%d, $%d, false)"
+	if s.db.Dialect == dbutil.SQLite {
+		placeholderSyntax = "(?1, ?%d, ?%d, false)"
+	}
+	for i, key := range preKeys {
+		baseIndex := 1 + i*2
+		values[baseIndex] = key.KeyID
+		values[baseIndex+1] = key.Priv[:]
+		queryParts[i] = fmt.Sprintf(placeholderSyntax, baseIndex+1, baseIndex+2)
+	}
+	_, err := s.db.Exec(ctx, putManyPreKeysQuery+strings.Join(queryParts, ","), values...)
+	return err
+}
+
 func scanPreKey(row dbutil.Scannable) (*keys.PreKey, error) {
 	var priv []byte
 	var id uint32
@@ -366,17 +442,37 @@ const (
 
 func (s *SQLStore) PutSenderKey(ctx context.Context, group, user string, session []byte) error {
 	_, err := s.db.Exec(ctx, putSenderKeyQuery, s.JID, group, user, session)
-	return err
+	if err != nil {
+		return err
+	}
+	s.signalCache.updateSenderKey(ctx, s.db, group, user, session)
+	return nil
 }
 
 func (s *SQLStore) GetSenderKey(ctx context.Context, group, user string) (key []byte, err error) {
+	if cached, ok := s.signalCache.getSenderKey(group, user); ok {
+		return cached, nil
+	}
+	generation := s.signalCache.startRead()
 	err = s.db.QueryRow(ctx, getSenderKeyQuery, s.JID, group, user).Scan(&key)
 	if errors.Is(err, sql.ErrNoRows) {
 		err = nil
 	}
+	if err == nil {
+		s.signalCache.populateSenderKey(ctx, s.db, generation, group, user, key)
+	}
 	return
 }
 
+func (s *SQLStore) DeleteSenderKey(ctx context.Context, group, user string) error {
+	_, err := s.db.Exec(ctx, deleteSenderKeyQuery, s.JID, group, user)
+	if err != nil {
+		return err
+	}
+	s.signalCache.updateSenderKey(ctx, s.db, group, user, nil)
+	return nil
+}
+
 const (
 	putAppStateSyncKeyQuery = `
 		INSERT INTO whatsmeow_app_state_sync_keys (jid, key_id, key_data, timestamp, fingerprint) VALUES ($1, $2, $3, $4, $5)
@@ -517,6 +613,11 @@ func (s *SQLStore) GetAppStateMutationMAC(ctx context.Context, name string, inde
 	return
 }
 
+func (s *SQLStore) DoAppStateTxn(ctx context.Context, fn func(context.Context) error) error {
+	ctx = context.WithValue(ctx, dbutil.ContextKeyDoTxnCallerSkip, 2)
+	return s.db.DoTxn(ctx, nil, fn)
+}
+
 const (
 	putContactNameQuery = `
 		INSERT INTO whatsmeow_contacts (our_jid, their_jid, first_name, full_name) VALUES ($1, $2, $3, $4)
@@ -527,6 +628,11 @@ const (
 		VALUES %s
 		ON CONFLICT (our_jid, their_jid) DO UPDATE SET first_name=excluded.first_name, full_name=excluded.full_name
 	`
+	putManyPushNamesQuery = `
+		INSERT INTO whatsmeow_contacts (our_jid, their_jid, push_name)
+		VALUES %s
+		ON CONFLICT (our_jid, their_jid) DO UPDATE SET push_name=excluded.push_name
+	`
 	putPushNameQuery = `
 		INSERT INTO whatsmeow_contacts (our_jid, their_jid, push_name) VALUES ($1, $2, $3)
 		ON CONFLICT (our_jid, their_jid) DO UPDATE SET push_name=excluded.push_name
@@ -541,6 +647,26 @@ const (
 	getAllContactsQuery = `
 		SELECT their_jid, first_name, full_name, push_name, business_name FROM whatsmeow_contacts WHERE our_jid=$1
 	`
+	getManyContactsQuery = `
+		SELECT their_jid, first_name, full_name, push_name, business_name FROM whatsmeow_contacts
+		WHERE our_jid=$1 AND their_jid IN (%s)
+	`
+	searchContactsQuery = `
+		SELECT their_jid, first_name, full_name, push_name, business_name FROM whatsmeow_contacts
+		WHERE our_jid=$1 AND (
+			LOWER(first_name) LIKE $2 ESCAPE '\' OR LOWER(full_name) LIKE $2 ESCAPE '\' OR
+			LOWER(push_name) LIKE $2 ESCAPE '\' OR LOWER(business_name) LIKE $2 ESCAPE '\'
+		)
+	`
+	putManyContactsQuery = `
+		INSERT INTO whatsmeow_contacts (our_jid, their_jid, first_name, full_name, push_name, business_name)
+		VALUES %s
+		ON CONFLICT (our_jid, their_jid) DO UPDATE SET
+			first_name=COALESCE(excluded.first_name, whatsmeow_contacts.first_name),
+			full_name=COALESCE(excluded.full_name, whatsmeow_contacts.full_name),
+			push_name=COALESCE(excluded.push_name, whatsmeow_contacts.push_name),
+			business_name=COALESCE(excluded.business_name, whatsmeow_contacts.business_name)
+	`
 )
 
 func (s *SQLStore) PutPushName(ctx context.Context, user types.JID, pushName string) (bool, string, error) {
@@ -661,6 +787,118 @@ func (s *SQLStore) PutAllContactNames(ctx context.Context, contacts []store.Cont
 	return nil
 }
 
+func (s *SQLStore) putPushNamesBatch(ctx context.Context, names []store.PushNameEntry) error {
+	values := make([]any, 1, 1+len(names)*2)
+	queryParts := make([]string, 0, len(names))
+	values[0] = s.JID
+	placeholderSyntax := "($1, $%d, $%d)"
+	if s.db.Dialect == dbutil.SQLite {
+		placeholderSyntax = "(?1, ?%d, ?%d)"
+	}
+	i := 0
+	handledNames := make(map[types.JID]struct{}, len(names))
+	for _, name := range names {
+		if name.JID.IsEmpty() {
+			s.log.Warnf("Empty push name info in mass insert: %+v", name)
+			continue
+		}
+		// The whole query will break if there are duplicates, so make sure there aren't any duplicates
+		_, alreadyHandled := handledNames[name.JID]
+		if alreadyHandled {
+			s.log.Warnf("Duplicate push name for %s in mass insert", name.JID)
+			continue
+		}
+		handledNames[name.JID] = struct{}{}
+		baseIndex := i*2 + 1
+		values = append(values, name.JID.String(), name.PushName)
+		queryParts = append(queryParts, fmt.Sprintf(placeholderSyntax, baseIndex+1, baseIndex+2))
+		i++
+	}
+	if len(queryParts) == 0 {
+		return nil
+	}
+	_, err := s.db.Exec(ctx, fmt.Sprintf(putManyPushNamesQuery, strings.Join(queryParts, ",")), values...)
+	return err
+}
+
+func (s *SQLStore) PutAllPushNames(ctx context.Context, names []store.PushNameEntry) error {
+	if len(names) == 0 {
+		return nil
+	}
+	err := s.db.DoTxn(ctx, nil, func(ctx context.Context) error {
+		for slice := range slices.Chunk(names, contactBatchSize) {
+			err := s.putPushNamesBatch(ctx, slice)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.contactCacheLock.Lock()
+	s.contactCache = make(map[types.JID]*types.ContactInfo)
+	s.contactCacheLock.Unlock()
+	return nil
+}
+
+func (s *SQLStore) putContactsBatch(ctx context.Context, jids []types.JID, contacts map[types.JID]types.ContactInfo) error {
+	values := make([]any, 1, 1+len(jids)*5)
+	queryParts := make([]string, 0, len(jids))
+	values[0] = s.JID
+	placeholderSyntax := "($1, $%d, $%d, $%d, $%d, $%d)"
+	if s.db.Dialect == dbutil.SQLite {
+		placeholderSyntax = "(?1, ?%d, ?%d, ?%d, ?%d, ?%d)"
+	}
+	for i, jid := range jids {
+		contact := contacts[jid]
+		baseIndex := i*5 + 1
+		values = append(
+			values, jid.String(),
+			nullableString(contact.FirstName), nullableString(contact.FullName),
+			nullableString(contact.PushName), nullableString(contact.BusinessName),
+		)
+		queryParts = append(queryParts, fmt.Sprintf(placeholderSyntax, baseIndex+1, baseIndex+2, baseIndex+3, baseIndex+4, baseIndex+5))
+	}
+	_, err := s.db.Exec(ctx, fmt.Sprintf(putManyContactsQuery, strings.Join(queryParts, ",")), values...)
+	return err
+}
+
+func nullableString(val string) sql.NullString {
+	return sql.NullString{String: val, Valid: val != ""}
+}
+
+func (s *SQLStore) PutAllContacts(ctx context.Context, contacts map[types.JID]types.ContactInfo) error {
+	jids := make([]types.JID, 0, len(contacts))
+	for jid := range contacts {
+		if jid.IsEmpty() {
+			s.log.Warnf("Empty contact JID in mass insert: %+v", contacts[jid])
+			continue
+		}
+		jids = append(jids, jid)
+	}
+	if len(jids) == 0 {
+		return nil
+	}
+	err := s.db.DoTxn(ctx, nil, func(ctx context.Context) error {
+		for slice := range slices.Chunk(jids, contactBatchSize) {
+			err := s.putContactsBatch(ctx, slice, contacts)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	s.contactCacheLock.Lock()
+	s.contactCache = make(map[types.JID]*types.ContactInfo)
+	s.contactCacheLock.Unlock()
+	return nil
+}
+
 func (s *SQLStore) getContact(ctx context.Context, user types.JID) (*types.ContactInfo, error) {
 	cached, ok := s.contactCache[user]
 	if ok {
@@ -693,6 +931,67 @@ func (s *SQLStore) GetContact(ctx context.Context, user types.JID) (types.Contac
 	return *info, nil
 }
 
+func (s *SQLStore) GetContacts(ctx context.Context, users []types.JID) (map[types.JID]types.ContactInfo, error) {
+	s.contactCacheLock.Lock()
+	defer s.contactCacheLock.Unlock()
+	output := make(map[types.JID]types.ContactInfo, len(users))
+	missing := make([]types.JID, 0, len(users))
+	for _, user := range users {
+		if cached, ok := s.contactCache[user]; ok {
+			if cached.Found {
+				output[user] = *cached
+			}
+		} else if !slices.Contains(missing, user) {
+			missing = append(missing, user)
+		}
+	}
+	for slice := range slices.Chunk(missing, contactBatchSize) {
+		values := make([]any, 1, 1+len(slice))
+		values[0] = s.JID
+		placeholders := make([]string, len(slice))
+		for i, user := range slice {
+			values = append(values, user)
+			if s.db.Dialect == dbutil.SQLite {
+				placeholders[i] = fmt.Sprintf("?%d", i+2)
+			} else {
+				placeholders[i] = fmt.Sprintf("$%d", i+2)
+			}
+		}
+		rows, err := s.db.Query(ctx, fmt.Sprintf(getManyContactsQuery, strings.Join(placeholders, ",")), values...)
+		if err != nil {
+			return nil, err
+		}
+		err = s.scanContacts(rows, output)
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range slice {
+			if _, found := output[user]; !found {
+				s.contactCache[user] = &types.ContactInfo{}
+			}
+		}
+	}
+	return output, nil
+}
+
+func (s *SQLStore) SearchContacts(ctx context.Context, query string) (map[types.JID]types.ContactInfo, error) {
+	pattern := "%" + likeEscaper.Replace(strings.ToLower(query)) + "%"
+	s.contactCacheLock.Lock()
+	defer s.contactCacheLock.Unlock()
+	rows, err := s.db.Query(ctx, searchContactsQuery, s.JID, pattern)
+	if err != nil {
+		return nil, err
+	}
+	output := make(map[types.JID]types.ContactInfo)
+	err = s.scanContacts(rows, output)
+	if err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
 func (s *SQLStore) GetAllContacts(ctx context.Context) (map[types.JID]types.ContactInfo, error) {
 	s.contactCacheLock.Lock()
 	defer s.contactCacheLock.Unlock()
@@ -701,12 +1000,22 @@ func (s *SQLStore) GetAllContacts(ctx context.Context) (map[types.JID]types.Cont
 		return nil, err
 	}
 	output := make(map[types.JID]types.ContactInfo, len(s.contactCache))
+	err = s.scanContacts(rows, output)
+	if err != nil {
+		return nil, err
+	}
+	return output, nil
+}
+
+// scanContacts reads contact rows into the given map and the contact cache. The contact cache lock must be held.
+func (s *SQLStore) scanContacts(rows dbutil.Rows, output map[types.JID]types.ContactInfo) error {
+	defer rows.Close()
 	for rows.Next() {
 		var jid types.JID
 		var first, full, push, business sql.NullString
-		err = rows.Scan(&jid, &first, &full, &push, &business)
+		err := rows.Scan(&jid, &first, &full, &push, &business)
 		if err != nil {
-			return nil, fmt.Errorf("error scanning row: %w", err)
+			return fmt.Errorf("error scanning row: %w", err)
 		}
 		info := types.ContactInfo{
 			Found:        true,
@@ -718,7 +1027,7 @@ func (s *SQLStore) GetAllContacts(ctx context.Context) (map[types.JID]types.Cont
 		output[jid] = info
 		s.contactCache[jid] = &info
 	}
-	return output, nil
+	return rows.Err()
 }
 
 const (
@@ -901,7 +1210,11 @@ func (s *SQLStore) PutBufferedEvent(ctx context.Context, ciphertextHash [32]byte
 
 func (s *SQLStore) DoDecryptionTxn(ctx context.Context, fn func(context.Context) error) error {
 	ctx = context.WithValue(ctx, dbutil.ContextKeyDoTxnCallerSkip, 2)
-	return s.db.DoTxn(ctx, nil, fn)
+	s.signalCache.beginTxn()
+	err := s.db.DoTxn(ctx, nil, fn)
+	// Entries written inside the transaction were only invalidated, so there's nothing to clean up on rollback
+	s.signalCache.endTxn(false)
+	return err
 }
 
 func (s *SQLStore) ClearBufferedEventPlaintext(ctx context.Context, ciphertextHash [32]byte) error {
@@ -915,3 +1228,111 @@ func (s *SQLStore) DeleteOldBufferedHashes(ctx context.Context) error {
 	_, err := s.db.Exec(ctx, deleteOldBufferedHashesQuery, time.Now().Add(-14*24*time.Hour).UnixMilli())
 	return err
 }
+
+const (
+	putOutboxMessageQuery = `
+		INSERT INTO whatsmeow_outbox (our_jid, message_id, recipient, message, created_at, attempts)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (our_jid, message_id) DO UPDATE SET recipient=excluded.recipient, message=excluded.message
+	`
+	getOutboxMessagesQuery = `
+		SELECT message_id, recipient, message, created_at, attempts FROM whatsmeow_outbox WHERE our_jid=$1 ORDER BY created_at ASC
+	`
+	incrementOutboxAttemptsQuery = `
+		UPDATE whatsmeow_outbox SET attempts=attempts+1 WHERE our_jid=$1 AND message_id=$2
+	`
+	deleteOutboxMessageQuery = `
+		DELETE FROM whatsmeow_outbox WHERE our_jid=$1 AND message_id=$2
+	`
+)
+
+func (s *SQLStore) PutOutboxMessage(ctx context.Context, msg *store.OutboxMessage) error {
+	_, err := s.db.Exec(ctx, putOutboxMessageQuery, s.JID, msg.ID, msg.To, msg.Message, msg.CreatedAt.UnixMilli(), msg.Attempts)
+	return err
+}
+
+func (s *SQLStore) GetOutboxMessages(ctx context.Context) ([]*store.OutboxMessage, error) {
+	rows, err := s.db.Query(ctx, getOutboxMessagesQuery, s.JID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var msgs []*store.OutboxMessage
+	for rows.Next() {
+		var msg store.OutboxMessage
+		var createdAt int64
+		err = rows.Scan(&msg.ID, &msg.To, &msg.Message, &createdAt, &msg.Attempts)
+		if err != nil {
+			return nil, err
+		}
+		msg.CreatedAt = time.UnixMilli(createdAt)
+		msgs = append(msgs, &msg)
+	}
+	return msgs, rows.Err()
+}
+
+func (s *SQLStore) IncrementOutboxAttempts(ctx context.Context, id types.MessageID) error {
+	_, err := s.db.Exec(ctx, incrementOutboxAttemptsQuery, s.JID, id)
+	return err
+}
+
+func (s *SQLStore) DeleteOutboxMessage(ctx context.Context, id types.MessageID) error {
+	_, err := s.db.Exec(ctx, deleteOutboxMessageQuery, s.JID, id)
+	return err
+}
+
+const (
+	getIdempotencyKeyQuery = `
+		SELECT chat_jid, message_id, created_at, sent_at FROM whatsmeow_idempotency_keys WHERE our_jid=$1 AND idempotency_key=$2
+	`
+	putIdempotencyKeyQuery = `
+		INSERT INTO whatsmeow_idempotency_keys (our_jid, idempotency_key, chat_jid, message_id, created_at) VALUES ($1, $2, $3, $4, $5)
+	`
+	markIdempotencyKeySentQuery = `
+		UPDATE whatsmeow_idempotency_keys SET sent_at=$3 WHERE our_jid=$1 AND idempotency_key=$2
+	`
+	deleteOldIdempotencyKeysQuery = `
+		DELETE FROM whatsmeow_idempotency_keys WHERE our_jid=$1 AND created_at < $2
+	`
+)
+
+func (s *SQLStore) GetIdempotencyKey(ctx context.Context, key string) (*store.IdempotencyKey, error) {
+	var createdAt int64
+	var sentAt sql.NullInt64
+	var chat string
+	output := store.IdempotencyKey{Key: key}
+	err := s.db.QueryRow(ctx, getIdempotencyKeyQuery, s.JID, key).Scan(&chat, &output.MessageID, &createdAt, &sentAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if chat != "" {
+		output.Chat, err = types.ParseJID(chat)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse chat JID: %w", err)
+		}
+	}
+	output.CreatedAt = time.UnixMilli(createdAt)
+	if sentAt.Valid {
+		output.SentAt = time.UnixMilli(sentAt.Int64)
+	}
+	return &output, nil
+}
+
+func (s *SQLStore) PutIdempotencyKey(ctx context.Context, key string, chat types.JID, id types.MessageID) error {
+	_, err := s.db.Exec(ctx, putIdempotencyKeyQuery, s.JID, key, chat.String(), id, time.Now().UnixMilli())
+	return err
+}
+
+func (s *SQLStore) MarkIdempotencyKeySent(ctx context.Context, key string, ts time.Time) error {
+	_, err := s.db.Exec(ctx, markIdempotencyKeySentQuery, s.JID, key, ts.UnixMilli())
+	return err
+}
+
+func (s *SQLStore) DeleteOldIdempotencyKeys(ctx context.Context) error {
+	// Callers are only expected to retry sends for a short while after a crash,
+	// so there's no need to keep keys around forever.
+	_, err := s.db.Exec(ctx, deleteOldIdempotencyKeysQuery, s.JID, time.Now().Add(-7*24*time.Hour).UnixMilli())
+	return err
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"go.mau.fi/whatsmeow/store/sqlstore/upgrades"
+)
+
+// SchemaVersion describes the schema version of a whatsmeow database.
+type SchemaVersion struct {
+	// The version the database is currently on. Zero means the database hasn't been initialized yet.
+	Current int
+	// The oldest schema version that the database is still compatible with.
+	Compatible int
+	// The latest schema version known by this version of whatsmeow.
+	Latest int
+}
+
+// NeedsUpgrade returns true if Container.Upgrade would run any upgrades.
+func (sv *SchemaVersion) NeedsUpgrade() bool {
+	return sv.Current < sv.Latest
+}
+
+// Supported returns false if the database was upgraded by a newer version of whatsmeow
+// in a way that isn't backwards-compatible.
+func (sv *SchemaVersion) Supported() bool {
+	return sv.Compatible <= sv.Latest
+}
+
+// GetSchemaVersion returns the current schema version of the database without changing anything.
+//
+// This can be used as a dry run before calling Upgrade, e.g. to refuse starting if an upgrade is needed.
+func (c *Container) GetSchemaVersion(ctx context.Context) (*SchemaVersion, error) {
+	sv := &SchemaVersion{Latest: len(upgrades.Table)}
+	if exists, err := c.db.TableExists(ctx, c.db.VersionTable); err != nil {
+		return nil, fmt.Errorf("failed to check if version table exists: %w", err)
+	} else if !exists {
+		return sv, nil
+	}
+	// Version tables created by old versions don't have the compat column. Upgrade would add it,
+	// but this method mustn't change anything, so just treat the compat version as missing.
+	compatExists, err := c.db.ColumnExists(ctx, c.db.VersionTable, "compat")
+	if err != nil {
+		return nil, fmt.Errorf("failed to check if compat column exists: %w", err)
+	}
+	var compat sql.NullInt32
+	if compatExists {
+		err = c.db.QueryRow(ctx, fmt.Sprintf("SELECT version, compat FROM %s LIMIT 1", c.db.VersionTable)).
+			Scan(&sv.Current, &compat)
+	} else {
+		err = c.db.QueryRow(ctx, fmt.Sprintf("SELECT version FROM %s LIMIT 1", c.db.VersionTable)).
+			Scan(&sv.Current)
+	}
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get current version: %w", err)
+	}
+	if compat.Valid && compat.Int32 != 0 {
+		sv.Compatible = int(compat.Int32)
+	} else {
+		sv.Compatible = sv.Current
+	}
+	return sv, nil
+}
+
+// upgradeLockID is the Postgres advisory lock key used to serialize upgrades across processes.
+const upgradeLockID int64 = 0x77686174736d656f // "whatsmeo"
+
+// upgradeWithLock runs all upgrades in a single transaction that holds a transaction-level advisory lock.
+// The lock is taken on the same connection as the upgrades, so it works even if the pool only has one connection,
+// and it's released automatically when the transaction is committed or rolled back.
+func (c *Container) upgradeWithLock(ctx context.Context) error {
+	return c.db.DoTxn(ctx, nil, func(ctx context.Context) error {
+		c.log.Debugf("Acquiring database upgrade lock")
+		_, err := c.db.Exec(ctx, "SELECT pg_advisory_xact_lock($1)", upgradeLockID)
+		if err != nil {
+			return fmt.Errorf("failed to acquire upgrade lock: %w", err)
+		}
+		return c.db.Upgrade(ctx)
+	})
+}
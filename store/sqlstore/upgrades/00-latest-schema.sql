@@ -1,4 +1,4 @@
--- v0 -> v10 (compatible with v8+): Latest schema
+-- v0 -> v14 (compatible with v8+): Latest schema
 CREATE TABLE whatsmeow_device (
 	jid TEXT PRIMARY KEY,
 	lid TEXT,
@@ -24,7 +24,11 @@ CREATE TABLE whatsmeow_device (
 	business_name TEXT NOT NULL DEFAULT '',
 	push_name     TEXT NOT NULL DEFAULT '',
 
-	lid_migration_ts BIGINT NOT NULL DEFAULT 0
+	lid_migration_ts BIGINT NOT NULL DEFAULT 0,
+
+	default_disappearing_timer BIGINT NOT NULL DEFAULT 0,
+
+	wa_version TEXT NOT NULL DEFAULT ''
 );
 
 CREATE TABLE whatsmeow_identity_keys (
@@ -153,3 +157,25 @@ CREATE TABLE whatsmeow_event_buffer (
 	PRIMARY KEY (our_jid, ciphertext_hash),
 	FOREIGN KEY (our_jid) REFERENCES whatsmeow_device(jid) ON DELETE CASCADE ON UPDATE CASCADE
 );
+
+CREATE TABLE whatsmeow_outbox (
+	our_jid    TEXT    NOT NULL,
+	message_id TEXT    NOT NULL,
+	recipient  TEXT    NOT NULL,
+	message    bytea   NOT NULL,
+	created_at BIGINT  NOT NULL,
+	attempts   INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY (our_jid, message_id),
+	FOREIGN KEY (our_jid) REFERENCES whatsmeow_device(jid) ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+CREATE TABLE whatsmeow_idempotency_keys (
+	our_jid         TEXT   NOT NULL,
+	idempotency_key TEXT   NOT NULL,
+	chat_jid        TEXT   NOT NULL,
+	message_id      TEXT   NOT NULL,
+	created_at      BIGINT NOT NULL,
+	sent_at         BIGINT,
+	PRIMARY KEY (our_jid, idempotency_key),
+	FOREIGN KEY (our_jid) REFERENCES whatsmeow_device(jid) ON DELETE CASCADE ON UPDATE CASCADE
+);
@@ -0,0 +1,2 @@
+-- v11 (compatible with v8+): Add default disappearing timer to device table
+ALTER TABLE whatsmeow_device ADD COLUMN default_disappearing_timer BIGINT NOT NULL DEFAULT 0;
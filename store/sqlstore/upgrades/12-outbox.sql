@@ -0,0 +1,11 @@
+-- v12 (compatible with v8+): Add outbox for persisted outgoing messages
+CREATE TABLE whatsmeow_outbox (
+	our_jid    TEXT    NOT NULL,
+	message_id TEXT    NOT NULL,
+	recipient  TEXT    NOT NULL,
+	message    bytea   NOT NULL,
+	created_at BIGINT  NOT NULL,
+	attempts   INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY (our_jid, message_id),
+	FOREIGN KEY (our_jid) REFERENCES whatsmeow_device(jid) ON DELETE CASCADE ON UPDATE CASCADE
+);
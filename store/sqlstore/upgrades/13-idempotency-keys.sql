@@ -0,0 +1,11 @@
+-- v13 (compatible with v8+): Add idempotency keys for outgoing messages
+CREATE TABLE whatsmeow_idempotency_keys (
+	our_jid         TEXT   NOT NULL,
+	idempotency_key TEXT   NOT NULL,
+	chat_jid        TEXT   NOT NULL,
+	message_id      TEXT   NOT NULL,
+	created_at      BIGINT NOT NULL,
+	sent_at         BIGINT,
+	PRIMARY KEY (our_jid, idempotency_key),
+	FOREIGN KEY (our_jid) REFERENCES whatsmeow_device(jid) ON DELETE CASCADE ON UPDATE CASCADE
+);
@@ -0,0 +1,2 @@
+-- v14 (compatible with v8+): Add web client version override to device table
+ALTER TABLE whatsmeow_device ADD COLUMN wa_version TEXT NOT NULL DEFAULT '';
@@ -9,11 +9,13 @@ package store
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
 
 	"go.mau.fi/whatsmeow/proto/waAdv"
+	"go.mau.fi/whatsmeow/proto/waCompanionReg"
 	"go.mau.fi/whatsmeow/types"
 	"go.mau.fi/whatsmeow/util/keys"
 	waLog "go.mau.fi/whatsmeow/util/log"
@@ -47,6 +49,7 @@ type PreKeyStore interface {
 type SenderKeyStore interface {
 	PutSenderKey(ctx context.Context, group, user string, session []byte) error
 	GetSenderKey(ctx context.Context, group, user string) ([]byte, error)
+	DeleteSenderKey(ctx context.Context, group, user string) error
 }
 
 type AppStateSyncKey struct {
@@ -74,6 +77,8 @@ type AppStateStore interface {
 	PutAppStateMutationMACs(ctx context.Context, name string, version uint64, mutations []AppStateMutationMAC) error
 	DeleteAppStateMutationMACs(ctx context.Context, name string, indexMACs [][]byte) error
 	GetAppStateMutationMAC(ctx context.Context, name string, indexMAC []byte) (valueMAC []byte, err error)
+
+	DoAppStateTxn(ctx context.Context, fn func(context.Context) error) error
 }
 
 type ContactEntry struct {
@@ -82,13 +87,26 @@ type ContactEntry struct {
 	FullName  string
 }
 
+type PushNameEntry struct {
+	JID      types.JID
+	PushName string
+}
+
 type ContactStore interface {
 	PutPushName(ctx context.Context, user types.JID, pushName string) (bool, string, error)
+	PutAllPushNames(ctx context.Context, names []PushNameEntry) error
 	PutBusinessName(ctx context.Context, user types.JID, businessName string) (bool, string, error)
 	PutContactName(ctx context.Context, user types.JID, fullName, firstName string) error
 	PutAllContactNames(ctx context.Context, contacts []ContactEntry) error
+	// PutAllContacts inserts or updates all the name fields of the given contacts, e.g. when importing contacts
+	// from an external source. Empty name fields don't overwrite existing values.
+	PutAllContacts(ctx context.Context, contacts map[types.JID]types.ContactInfo) error
 	GetContact(ctx context.Context, user types.JID) (types.ContactInfo, error)
+	// GetContacts gets the info of multiple contacts at once. Contacts that aren't found are not included in the map.
+	GetContacts(ctx context.Context, users []types.JID) (map[types.JID]types.ContactInfo, error)
 	GetAllContacts(ctx context.Context) (map[types.JID]types.ContactInfo, error)
+	// SearchContacts returns all contacts whose first, full, push or business name contains the given query, case-insensitively.
+	SearchContacts(ctx context.Context, query string) (map[types.JID]types.ContactInfo, error)
 }
 
 var MutedForever = time.Date(9999, 12, 31, 23, 59, 59, 999999999, time.UTC)
@@ -143,6 +161,39 @@ type EventBuffer interface {
 	DeleteOldBufferedHashes(ctx context.Context) error
 }
 
+// OutboxMessage is an outgoing message that has been persisted, but not yet acknowledged by the server.
+type OutboxMessage struct {
+	ID        types.MessageID
+	To        types.JID
+	Message   []byte // The protobuf-encoded waE2E.Message
+	CreatedAt time.Time
+	Attempts  int
+}
+
+type OutboxStore interface {
+	PutOutboxMessage(ctx context.Context, msg *OutboxMessage) error
+	GetOutboxMessages(ctx context.Context) ([]*OutboxMessage, error)
+	IncrementOutboxAttempts(ctx context.Context, id types.MessageID) error
+	DeleteOutboxMessage(ctx context.Context, id types.MessageID) error
+}
+
+// IdempotencyKey is a caller-provided key that was used to send a message, see SendRequestExtra.IdempotencyKey.
+type IdempotencyKey struct {
+	Key       string
+	Chat      types.JID
+	MessageID types.MessageID
+	CreatedAt time.Time
+	// The server timestamp of the message, or zero if the message hasn't been acknowledged by the server yet.
+	SentAt time.Time
+}
+
+type IdempotencyStore interface {
+	GetIdempotencyKey(ctx context.Context, key string) (*IdempotencyKey, error)
+	PutIdempotencyKey(ctx context.Context, key string, chat types.JID, id types.MessageID) error
+	MarkIdempotencyKeySent(ctx context.Context, key string, ts time.Time) error
+	DeleteOldIdempotencyKeys(ctx context.Context) error
+}
+
 type LIDMapping struct {
 	LID types.JID
 	PN  types.JID
@@ -167,6 +218,8 @@ type AllSessionSpecificStores interface {
 	MsgSecretStore
 	PrivacyTokenStore
 	EventBuffer
+	OutboxStore
+	IdempotencyStore
 }
 
 type AllGlobalStores interface {
@@ -178,10 +231,31 @@ type AllStores interface {
 	AllGlobalStores
 }
 
+// ErrNoNoiseKey is returned when trying to use or store a device that has neither NoiseKey nor ExternalNoiseKey set.
+var ErrNoNoiseKey = errors.New("device has no noise key")
+
+// ExternalNoiseKey is a noise key whose private half is stored outside of whatsmeow, e.g. in a HSM, TPM or cloud KMS.
+//
+// Only the noise key can be stored externally. The Signal identity key and pre-keys are always stored
+// in the database in plaintext, so the database must still be protected accordingly.
+type ExternalNoiseKey interface {
+	// PublicKey returns the public half of the key.
+	PublicKey() *[32]byte
+	// X25519 performs a Diffie-Hellman key agreement between the private key and the given public key.
+	X25519(ctx context.Context, peer [32]byte) ([]byte, error)
+}
+
 type Device struct {
 	Log waLog.Logger
 
-	NoiseKey       *keys.KeyPair
+	NoiseKey *keys.KeyPair
+	// ExternalNoiseKey can be set to use a noise key that isn't stored in the database.
+	// When it's set, NoiseKey is ignored and can be nil.
+	//
+	// The Signal identity key can't be stored externally, as libsignal requires direct access to it.
+	// It's still stored in the database in plaintext even if the noise key is external.
+	ExternalNoiseKey ExternalNoiseKey
+
 	IdentityKey    *keys.KeyPair
 	SignedPreKey   *keys.PreKey
 	RegistrationID uint32
@@ -196,6 +270,22 @@ type Device struct {
 
 	LIDMigrationTimestamp int64
 
+	// WAVersion overrides the global web client version (see SetWAVersion) for this device if set.
+	// Unlike the global version, this is persisted in the database. Use Client.SetWAVersion to change it at runtime.
+	WAVersion WAVersionContainer
+
+	// RelinkFrom is set by Client.RelinkDevice to the JID of the logged out device whose local data
+	// should be moved to this device when it's saved after pairing.
+	RelinkFrom *types.JID
+
+	// DeviceProps can be set to override the global DeviceProps (name, platform type, etc.) when pairing this device.
+	// It is not persisted, as it's only used during pairing. See NewDeviceProps for a helper.
+	DeviceProps *waCompanionReg.DeviceProps
+
+	// The last known default disappearing message timer for new chats. This is not fetched from the server on connect,
+	// see Client.SetDefaultDisappearingTimer for when it is updated.
+	DefaultDisappearingTimer time.Duration
+
 	FacebookUUID uuid.UUID
 
 	Initialized   bool
@@ -210,6 +300,8 @@ type Device struct {
 	MsgSecrets    MsgSecretStore
 	PrivacyTokens PrivacyTokenStore
 	EventBuffer   EventBuffer
+	Outbox        OutboxStore
+	Idempotency   IdempotencyStore
 	LIDs          LIDStore
 	Container     DeviceContainer
 }
@@ -225,6 +317,17 @@ func (device *Device) GetJID() types.JID {
 	return *id
 }
 
+// GetNoisePublicKey returns the public key used for the noise handshake,
+// taking ExternalNoiseKey into account. If neither key is set, this returns nil.
+func (device *Device) GetNoisePublicKey() *[32]byte {
+	if device.ExternalNoiseKey != nil {
+		return device.ExternalNoiseKey.PublicKey()
+	} else if device.NoiseKey != nil {
+		return device.NoiseKey.Pub
+	}
+	return nil
+}
+
 func (device *Device) GetLID() types.JID {
 	if device == nil {
 		return types.EmptyJID
@@ -232,6 +335,31 @@ func (device *Device) GetLID() types.JID {
 	return device.LID
 }
 
+// IsMuted checks if the given chat is currently muted according to the chat settings received via app state.
+func (device *Device) IsMuted(ctx context.Context, chat types.JID) (bool, error) {
+	settings, err := device.ChatSettings.GetChatSettings(ctx, chat)
+	return settings.IsMuted(), err
+}
+
+// GetMutedUntil returns the time until which the given chat is muted.
+// The returned time is zero if the chat isn't muted, and MutedForever if it's muted indefinitely.
+func (device *Device) GetMutedUntil(ctx context.Context, chat types.JID) (time.Time, error) {
+	settings, err := device.ChatSettings.GetChatSettings(ctx, chat)
+	return settings.MutedUntil, err
+}
+
+// IsArchived checks if the given chat is archived according to the chat settings received via app state.
+func (device *Device) IsArchived(ctx context.Context, chat types.JID) (bool, error) {
+	settings, err := device.ChatSettings.GetChatSettings(ctx, chat)
+	return settings.Archived, err
+}
+
+// IsPinned checks if the given chat is pinned according to the chat settings received via app state.
+func (device *Device) IsPinned(ctx context.Context, chat types.JID) (bool, error) {
+	settings, err := device.ChatSettings.GetChatSettings(ctx, chat)
+	return settings.Pinned, err
+}
+
 func (device *Device) Save(ctx context.Context) error {
 	return device.Container.PutDevice(ctx, device)
 }
@@ -133,6 +133,14 @@ type UnarchiveChatsSetting struct {
 	FromFullSync bool                                // Whether the action is emitted because of a fullSync
 }
 
+// LocaleSetting is emitted when the user changes the app language from another device.
+type LocaleSetting struct {
+	Timestamp time.Time // The time when the setting was changed.
+
+	Action       *waSyncAction.LocaleSetting // The new locale.
+	FromFullSync bool                        // Whether the action is emitted because of a fullSync
+}
+
 // UserStatusMute is emitted when the user mutes or unmutes another user's status updates.
 type UserStatusMute struct {
 	JID       types.JID // The user who was muted or unmuted
@@ -179,7 +187,27 @@ type AppState struct {
 	*waSyncAction.SyncActionValue
 }
 
+// RawAppStateMutation is emitted for app state mutations with an index type that doesn't have a dedicated event.
+//
+// Unlike AppState, this is only emitted for unrecognized mutations, so it can be used to handle
+// new features before whatsmeow supports them without duplicating the handling of known mutations.
+type RawAppStateMutation struct {
+	Index     []string  // The decoded index, where the first item is the mutation type.
+	Timestamp time.Time // The time when the mutation happened.
+
+	Action       *waSyncAction.SyncActionValue // The raw value of the mutation.
+	FromFullSync bool                          // Whether the action is emitted because of a fullSync
+}
+
 // AppStateSyncComplete is emitted when app state is resynced.
 type AppStateSyncComplete struct {
 	Name appstate.WAPatchName
 }
+
+// AppStateSyncKeysReceived is emitted when app state sync keys are received from the primary device,
+// either as a response to a key request or when the primary device rotates the keys.
+//
+// App state patches that previously failed to decrypt due to missing keys are resynced automatically after this event.
+type AppStateSyncKeysReceived struct {
+	KeyIDs [][]byte
+}
@@ -116,6 +116,19 @@ type LoggedOut struct {
 // or otherwise try to connect twice with the same session.
 type StreamReplaced struct{}
 
+// ServiceUnavailable is emitted when the server closes the connection with a 503 stream error or connect failure,
+// which usually means the server is restarting or under maintenance. The client will reconnect automatically
+// if auto-reconnect is enabled.
+type ServiceUnavailable struct {
+	// OnConnect is true if the event was triggered by a connect failure message.
+	// If it's false, the event was triggered by a stream:error message.
+	OnConnect bool
+	// The delay requested by the server before reconnecting, or zero if the server didn't include one.
+	RetryAfter time.Duration
+	Message    string
+	Raw        *waBinary.Node
+}
+
 // ManualLoginReconnect is emitted after login if DisableLoginAutoReconnect is set.
 type ManualLoginReconnect struct{}
 
@@ -246,6 +259,21 @@ type HistorySync struct {
 	Data *waHistorySync.HistorySync
 }
 
+// HistorySyncProgress is emitted after each history sync chunk has been handled (i.e. after the [HistorySync] event for it).
+//
+// Chunks of each sync type are dispatched in chunk order. If a chunk is missing for too long,
+// the later chunks are dispatched anyway (see HistorySyncChunkWaitTimeout in the whatsmeow package).
+//
+// This is not emitted if Client.ManualHistorySyncDownload is enabled.
+type HistorySyncProgress struct {
+	SyncType   waE2E.HistorySyncNotification_HistorySyncType
+	ChunkOrder uint32
+	// Progress is the overall progress percentage of the sync, as reported by the phone.
+	Progress uint32
+	// Failed is true if downloading or parsing the chunk failed, which means no HistorySync event was dispatched for it.
+	Failed bool
+}
+
 type DecryptFailMode string
 
 const (
@@ -278,6 +306,47 @@ type UndecryptableMessage struct {
 	DecryptFailMode DecryptFailMode
 }
 
+// KeepInChat is emitted when someone keeps or unkeeps a message in a chat with disappearing messages enabled.
+//
+// The keep message itself is also emitted as a normal Message event with the KeepInChatMessage field set.
+type KeepInChat struct {
+	Info types.MessageInfo // Information about the keep message
+
+	TargetID     types.MessageID // The ID of the message that was kept or unkept
+	TargetSender types.JID       // The sender of the message that was kept or unkept
+	Keep         bool            // True if the message was kept, false if it was unkept
+	Timestamp    time.Time
+}
+
+// EventResponse is emitted when someone responds to an event (calendar invite) message in a group.
+//
+// The response is decrypted automatically. The encrypted message is also emitted as a normal Message event
+// with the EncEventResponseMessage field set, which can be decrypted manually using Client.DecryptEventResponse.
+type EventResponse struct {
+	Info types.MessageInfo // Information about the response message
+
+	EventID         types.MessageID // The ID of the event message that was responded to
+	EventSender     types.JID       // The sender of the event message
+	Response        waE2E.EventResponseMessage_EventResponseType
+	ExtraGuestCount int
+	Timestamp       time.Time
+}
+
+// OutboxMessageSent is emitted when a message queued with Client.EnqueueMessage is acknowledged by the server.
+type OutboxMessageSent struct {
+	ID        types.MessageID
+	To        types.JID
+	Timestamp time.Time // The server timestamp of the message
+}
+
+// OutboxMessageFailed is emitted when a message queued with Client.EnqueueMessage is dropped
+// from the outbox after failing to send too many times.
+type OutboxMessageFailed struct {
+	ID    types.MessageID
+	To    types.JID
+	Error error
+}
+
 type NewsletterMessageMeta struct {
 	// When a newsletter message is edited, the message isn't wrapped in an EditedMessage like normal messages.
 	// Instead, the message is the new content, the ID is the original message ID, and the edit timestamp is here.
@@ -298,7 +367,10 @@ type Message struct {
 	IsDocumentWithCaption bool // True if the message was unwrapped from a DocumentWithCaptionMessage
 	IsLottieSticker       bool // True if the message was unwrapped from a LottieStickerMessage
 	IsBotInvoke           bool // True if the message was unwrapped from a BotInvokeMessage
+	IsAssociatedChild     bool // True if the message was unwrapped from an AssociatedChildMessage (e.g. an item in an album)
 	IsEdit                bool // True if the message was unwrapped from an EditedMessage
+	IsCaptionEdit         bool // True if the message is an edit that changes the caption of a media message rather than the text of a text message
+	IsStatusReply         bool // True if the message is a private reply or reaction to a status broadcast message
 
 	// If this event was parsed from a WebMessageInfo (i.e. from a history sync or unavailable message request), the source data is here.
 	SourceWebMsg *waWeb.WebMessageInfo
@@ -309,6 +381,9 @@ type Message struct {
 
 	NewsletterMeta *NewsletterMessageMeta
 
+	// If the message is an image or video in an album, this is the ID of the album message (which has the AlbumMessage field set).
+	AlbumID types.MessageID
+
 	// The raw message struct. This is the raw unmodified data, which means the actual message might
 	// be wrapped in DeviceSentMessage, EphemeralMessage or ViewOnceMessage.
 	RawMessage *waE2E.Message
@@ -359,6 +434,10 @@ func (evt *Message) UnwrapRaw() *Message {
 		evt.Message = evt.Message.GetEphemeralMessage().GetMessage()
 		evt.IsEphemeral = true
 	}
+	if evt.Message.GetAssociatedChildMessage().GetMessage() != nil {
+		evt.Message = evt.Message.GetAssociatedChildMessage().GetMessage()
+		evt.IsAssociatedChild = true
+	}
 	if evt.Message.GetViewOnceMessage().GetMessage() != nil {
 		evt.Message = evt.Message.GetViewOnceMessage().GetMessage()
 		evt.IsViewOnce = true
@@ -386,12 +465,83 @@ func (evt *Message) UnwrapRaw() *Message {
 		evt.Message = evt.Message.GetEditedMessage().GetMessage()
 		evt.IsEdit = true
 	}
+	if evt.IsEdit || evt.Message.GetProtocolMessage().GetType() == waE2E.ProtocolMessage_MESSAGE_EDIT {
+		evt.IsCaptionEdit = isMediaMessage(evt.Message.GetProtocolMessage().GetEditedMessage())
+	}
 	if evt.Message != nil && evt.RawMessage != nil && evt.Message.MessageContextInfo == nil && evt.RawMessage.MessageContextInfo != nil {
 		evt.Message.MessageContextInfo = evt.RawMessage.MessageContextInfo
 	}
+	if assoc := evt.Message.GetMessageContextInfo().GetMessageAssociation(); assoc.GetAssociationType() == waE2E.MessageAssociation_MEDIA_ALBUM {
+		evt.AlbumID = assoc.GetParentMessageKey().GetID()
+	}
+	evt.IsStatusReply = evt.Info.Chat != types.StatusBroadcastJID && isStatusReply(evt.Message)
 	return evt
 }
 
+// MessageRevoke is emitted instead of Message for incoming revokes (i.e. delete for everyone)
+// if Client.DispatchRevokeEvents is enabled.
+type MessageRevoke struct {
+	// Info about the revoke message itself. The chat is the same as the chat of the revoked message.
+	Info types.MessageInfo
+	// The ID of the message that was revoked.
+	RevokedMessageID types.MessageID
+	// The sender of the message that was revoked.
+	RevokedMessageSender types.JID
+	// If the message was deleted by a group admin rather than the sender, this is the admin who deleted it.
+	AdminSender types.JID
+
+	RawMessage *waE2E.Message
+}
+
+// AsRevoke returns a MessageRevoke event if the message is a revoke, or nil otherwise.
+func (evt *Message) AsRevoke() *MessageRevoke {
+	protoMsg := evt.Message.GetProtocolMessage()
+	if protoMsg.GetType() != waE2E.ProtocolMessage_REVOKE {
+		return nil
+	}
+	revoke := &MessageRevoke{
+		Info:                 evt.Info,
+		RevokedMessageID:     protoMsg.GetKey().GetID(),
+		RevokedMessageSender: evt.Info.Sender,
+		RawMessage:           evt.RawMessage,
+	}
+	if participant := protoMsg.GetKey().GetParticipant(); participant != "" {
+		revoke.RevokedMessageSender, _ = types.ParseJID(participant)
+	}
+	if evt.Info.Edit == types.EditAttributeAdminRevoke {
+		revoke.AdminSender = evt.Info.Sender
+	}
+	return revoke
+}
+
+func isMediaMessage(msg *waE2E.Message) bool {
+	return msg.GetImageMessage() != nil ||
+		msg.GetVideoMessage() != nil ||
+		msg.GetDocumentMessage() != nil ||
+		msg.GetDocumentWithCaptionMessage().GetMessage().GetDocumentMessage() != nil
+}
+
+func isStatusReply(msg *waE2E.Message) bool {
+	statusJID := types.StatusBroadcastJID.String()
+	if msg.GetReactionMessage() != nil {
+		return msg.GetReactionMessage().GetKey().GetRemoteJID() == statusJID
+	}
+	var ctxInfo *waE2E.ContextInfo
+	switch {
+	case msg.GetExtendedTextMessage() != nil:
+		ctxInfo = msg.GetExtendedTextMessage().GetContextInfo()
+	case msg.GetImageMessage() != nil:
+		ctxInfo = msg.GetImageMessage().GetContextInfo()
+	case msg.GetVideoMessage() != nil:
+		ctxInfo = msg.GetVideoMessage().GetContextInfo()
+	case msg.GetAudioMessage() != nil:
+		ctxInfo = msg.GetAudioMessage().GetContextInfo()
+	case msg.GetStickerMessage() != nil:
+		ctxInfo = msg.GetStickerMessage().GetContextInfo()
+	}
+	return ctxInfo.GetRemoteJID() == statusJID && ctxInfo.GetStanzaID() != ""
+}
+
 // Deprecated: use types.ReceiptType directly
 type ReceiptType = types.ReceiptType
 
@@ -491,6 +641,10 @@ type GroupInfo struct {
 	Promote []types.JID // Users who were promoted to admins
 	Demote  []types.JID // Users who were demoted to normal users
 
+	MembershipRequestsCreated []types.JID // Users who requested to join the group (only sent to admins)
+	MembershipRequestsRevoked []types.JID // Users whose join requests were cancelled
+	MembershipRequestMethod   string      // How the join requests were created, e.g. "invite_link"
+
 	UnknownChanges []*waBinary.Node
 }
 
@@ -505,6 +659,14 @@ type Picture struct {
 	PictureID string    // The new picture ID if it was not removed.
 }
 
+// UnknownAccountSync is emitted when an account sync notification contains an item that whatsmeow doesn't handle.
+//
+// Account sync notifications are sent when settings of the current account are changed from another device.
+type UnknownAccountSync struct {
+	Timestamp time.Time
+	Node      *waBinary.Node
+}
+
 // UserAbout is emitted when a user's about status is changed.
 type UserAbout struct {
 	JID       types.JID // The user whose status was changed
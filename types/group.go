@@ -41,6 +41,9 @@ type GroupInfo struct {
 
 	ParticipantVersionID string
 	Participants         []GroupParticipant
+	// The total number of participants in the group. This is mostly useful for GetGroupInfoFromLink,
+	// where the participant list may be incomplete. It may be zero if the server didn't include the size.
+	ParticipantCount int
 
 	MemberAddMode GroupMemberAddMode
 }
@@ -41,6 +41,26 @@ func (ms *MessageSource) IsIncomingBroadcast() bool {
 	return (!ms.IsFromMe || !ms.BroadcastListOwner.IsEmpty()) && ms.Chat.IsBroadcastList()
 }
 
+// SenderPN returns the phone number JID of the sender, or an empty JID if it's not known.
+func (ms *MessageSource) SenderPN() JID {
+	if ms.Sender.Server == DefaultUserServer {
+		return ms.Sender
+	} else if ms.SenderAlt.Server == DefaultUserServer {
+		return ms.SenderAlt
+	}
+	return EmptyJID
+}
+
+// SenderLID returns the hidden user (LID) JID of the sender, or an empty JID if it's not known.
+func (ms *MessageSource) SenderLID() JID {
+	if ms.Sender.Server == HiddenUserServer {
+		return ms.Sender
+	} else if ms.SenderAlt.Server == HiddenUserServer {
+		return ms.SenderAlt
+	}
+	return EmptyJID
+}
+
 // DeviceSentMeta contains metadata from messages sent by another one of the user's own devices.
 type DeviceSentMeta struct {
 	DestinationJID string // The destination user. This should match the MessageInfo.Recipient field.
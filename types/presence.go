@@ -55,12 +55,25 @@ const (
 	// and has read receipts disabled in privacy settings.
 	ReceiptTypePlayedSelf ReceiptType = "played-self"
 
+	// ReceiptTypeServerError means the server failed to process a message, e.g. when sending to a newsletter.
 	ReceiptTypeServerError ReceiptType = "server-error"
-	ReceiptTypeInactive    ReceiptType = "inactive"
-	ReceiptTypePeerMsg     ReceiptType = "peer_msg"
+	// ReceiptTypeInactive means the message was delivered to a device that isn't actively in use (e.g. a phone
+	// with the app in the background). It should be treated like a normal delivery receipt.
+	ReceiptTypeInactive ReceiptType = "inactive"
+	// ReceiptTypePeerMsg is sent by your other devices when they receive a peer message
+	// (a protocol message between your own devices, like an app state key share).
+	ReceiptTypePeerMsg ReceiptType = "peer_msg"
+	// ReceiptTypeHistorySync is sent by your other devices when they've processed a history sync message.
 	ReceiptTypeHistorySync ReceiptType = "hist_sync"
 )
 
+// IsDelivery returns true if the receipt type means the message was delivered to the recipient's device.
+//
+// This doesn't include ReceiptTypeSender, which only means the message was delivered to your own other devices.
+func (rt ReceiptType) IsDelivery() bool {
+	return rt == ReceiptTypeDelivered || rt == ReceiptTypeInactive
+}
+
 // GoString returns the name of the Go constant for the ReceiptType value.
 func (rt ReceiptType) GoString() string {
 	switch rt {
@@ -72,6 +85,20 @@ func (rt ReceiptType) GoString() string {
 		return "types.ReceiptTypeDelivered"
 	case ReceiptTypePlayed:
 		return "types.ReceiptTypePlayed"
+	case ReceiptTypePlayedSelf:
+		return "types.ReceiptTypePlayedSelf"
+	case ReceiptTypeSender:
+		return "types.ReceiptTypeSender"
+	case ReceiptTypeRetry:
+		return "types.ReceiptTypeRetry"
+	case ReceiptTypeServerError:
+		return "types.ReceiptTypeServerError"
+	case ReceiptTypeInactive:
+		return "types.ReceiptTypeInactive"
+	case ReceiptTypePeerMsg:
+		return "types.ReceiptTypePeerMsg"
+	case ReceiptTypeHistorySync:
+		return "types.ReceiptTypeHistorySync"
 	default:
 		return fmt.Sprintf("types.ReceiptType(%#v)", string(rt))
 	}
@@ -0,0 +1,55 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+package types
+
+import (
+	"strconv"
+	"time"
+)
+
+// Props contains server-provided configuration values, such as feature flags and limits.
+//
+// Server props are keyed by name, while A/B props are keyed by their numeric config code.
+type Props struct {
+	Hash string
+	// How often the server wants the props to be refetched, if specified.
+	RefreshInterval time.Duration
+	Values          map[string]string
+}
+
+// Get returns the raw value of the given prop.
+func (p *Props) Get(key string) (string, bool) {
+	if p == nil {
+		return "", false
+	}
+	val, ok := p.Values[key]
+	return val, ok
+}
+
+// GetInt returns the value of the given prop as an integer, or the given default value if the prop isn't set or isn't a number.
+func (p *Props) GetInt(key string, defaultValue int) int {
+	val, ok := p.Get(key)
+	if !ok {
+		return defaultValue
+	}
+	intVal, err := strconv.Atoi(val)
+	if err != nil {
+		return defaultValue
+	}
+	return intVal
+}
+
+// GetBool returns the value of the given prop as a boolean, or the given default value if the prop isn't set.
+//
+// Both "1" and "true" are considered true.
+func (p *Props) GetBool(key string, defaultValue bool) bool {
+	val, ok := p.Get(key)
+	if !ok {
+		return defaultValue
+	}
+	return val == "1" || val == "true"
+}
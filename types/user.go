@@ -22,10 +22,31 @@ type VerifiedName struct {
 type UserInfo struct {
 	VerifiedName *VerifiedName
 	Status       string
+	StatusSetAt  time.Time
 	PictureID    string
 	Devices      []JID
 }
 
+// UserAbout contains the about text of a WhatsApp user.
+type UserAbout struct {
+	Status string
+	SetAt  time.Time
+}
+
+// LinkedDevice contains info about one of the devices linked to the current account.
+type LinkedDevice struct {
+	JID JID
+	// The index of the device in the account's signed device list. Zero for the primary device.
+	KeyIndex uint32
+	// True if this is the device the client is logged in as.
+	IsCurrent bool
+}
+
+// IsPrimary returns true if the device is the account's primary device (i.e. the phone).
+func (ld LinkedDevice) IsPrimary() bool {
+	return ld.JID.Device == 0
+}
+
 type BotListInfo struct {
 	BotJID    JID
 	PersonaID string
@@ -79,6 +100,11 @@ type LocalChatSettings struct {
 	Archived   bool
 }
 
+// IsMuted returns true if the chat is currently muted.
+func (lcs LocalChatSettings) IsMuted() bool {
+	return !lcs.MutedUntil.IsZero() && lcs.MutedUntil.After(time.Now())
+}
+
 // IsOnWhatsAppResponse contains information received in response to checking if a phone number is on WhatsApp.
 type IsOnWhatsAppResponse struct {
 	Query string // The query string used
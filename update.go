@@ -22,6 +22,9 @@ var clientVersionRegex = regexp.MustCompile(`"client_revision":(\d+),`)
 
 // GetLatestVersion returns the latest version number from web.whatsapp.com.
 //
+// If httpClient is nil, http.DefaultClient is used. To use the same HTTP client as media requests,
+// pass Client.GetMediaHTTPClient().
+//
 // After fetching, you can update the version to use using store.SetWAVersion, e.g.
 //
 //	latestVer, err := GetLatestVersion(nil)
@@ -62,3 +65,20 @@ func GetLatestVersion(ctx context.Context, httpClient *http.Client) (*store.WAVe
 		return &store.WAVersionContainer{2, 3000, uint32(parsedVer)}, nil
 	}
 }
+
+// SetWAVersion overrides the web client version advertised by this client and saves it in the device store,
+// so it'll be used after restarts too. Passing a zero version clears the override and reverts to the global
+// version (see store.SetWAVersion).
+//
+// The new version is used starting from the next connection, so call this before Connect or reconnect afterwards.
+// If the device isn't paired yet, the version will be saved after pairing.
+func (cli *Client) SetWAVersion(ctx context.Context, version store.WAVersionContainer) error {
+	if cli == nil {
+		return ErrClientIsNil
+	}
+	cli.Store.WAVersion = version
+	if cli.Store.ID == nil {
+		return nil
+	}
+	return cli.Store.Save(ctx)
+}
@@ -13,11 +13,13 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"os"
+	"slices"
 
 	"go.mau.fi/util/random"
 
@@ -212,14 +214,48 @@ func (cli *Client) rawUpload(ctx context.Context, dataToUpload io.Reader, upload
 		mmsType = fmt.Sprintf("newsletter-%s", mmsType)
 		uploadPrefix = "newsletter"
 	}
-	var host string
+	if len(mediaConn.Hosts) == 0 {
+		return fmt.Errorf("no media hosts available")
+	}
+	hosts := slices.Clone(mediaConn.Hosts)
 	// Hacky hack to prefer last option (rupload.facebook.com) for messenger uploads.
 	// For some reason, the primary host doesn't work, even though it has the <upload/> tag.
 	if cli.MessengerConfig != nil {
-		host = mediaConn.Hosts[len(mediaConn.Hosts)-1].Hostname
-	} else {
-		host = mediaConn.Hosts[0].Hostname
+		slices.Reverse(hosts)
+	}
+	seeker, canRetry := dataToUpload.(io.Seeker)
+	for i, host := range hosts {
+		if i > 0 {
+			if _, err = seeker.Seek(0, io.SeekStart); err != nil {
+				return fmt.Errorf("failed to seek to start of upload data for retry: %w", err)
+			}
+		}
+		err = cli.uploadToHost(ctx, host.Hostname, uploadPrefix, mmsType, token, q, dataToUpload, uploadSize, resp)
+		var statusErr *uploadStatusError
+		if err == nil || !canRetry || i >= len(hosts)-1 || !errors.As(err, &statusErr) || statusErr.StatusCode < 500 {
+			return err
+		}
+		cli.Log.Warnf("Failed to upload media to %s: %v, trying with next host...", host.Hostname, err)
 	}
+	return err
+}
+
+type uploadStatusError struct {
+	StatusCode int
+}
+
+func (use *uploadStatusError) Error() string {
+	return fmt.Sprintf("upload failed with status code %d", use.StatusCode)
+}
+
+func (cli *Client) uploadToHost(
+	ctx context.Context,
+	host, uploadPrefix, mmsType, token string,
+	q url.Values,
+	dataToUpload io.Reader,
+	uploadSize uint64,
+	resp *UploadResponse,
+) error {
 	uploadURL := url.URL{
 		Scheme:   "https",
 		Host:     host,
@@ -240,7 +276,7 @@ func (cli *Client) rawUpload(ctx context.Context, dataToUpload io.Reader, upload
 	if err != nil {
 		err = fmt.Errorf("failed to execute request: %w", err)
 	} else if httpResp.StatusCode != http.StatusOK {
-		err = fmt.Errorf("upload failed with status code %d", httpResp.StatusCode)
+		err = &uploadStatusError{StatusCode: httpResp.StatusCode}
 	} else if err = json.NewDecoder(httpResp.Body).Decode(&resp); err != nil {
 		err = fmt.Errorf("failed to parse upload response: %w", err)
 	}
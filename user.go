@@ -13,12 +13,15 @@ import (
 	"fmt"
 	"slices"
 	"strings"
+	"sync"
+	"time"
 
 	"google.golang.org/protobuf/proto"
 
 	waBinary "go.mau.fi/whatsmeow/binary"
 	"go.mau.fi/whatsmeow/proto/waHistorySync"
 	"go.mau.fi/whatsmeow/proto/waVnameCert"
+	"go.mau.fi/whatsmeow/store"
 	"go.mau.fi/whatsmeow/types"
 	"go.mau.fi/whatsmeow/types/events"
 )
@@ -165,11 +168,16 @@ func (cli *Client) SetStatusMessage(msg string) error {
 // IsOnWhatsApp checks if the given phone numbers are registered on WhatsApp.
 // The phone numbers should be in international format, including the `+` prefix.
 func (cli *Client) IsOnWhatsApp(phones []string) ([]types.IsOnWhatsAppResponse, error) {
+	return cli.IsOnWhatsAppContext(context.TODO(), phones)
+}
+
+// IsOnWhatsAppContext is like IsOnWhatsApp, but the given context can be used to cancel the query.
+func (cli *Client) IsOnWhatsAppContext(ctx context.Context, phones []string) ([]types.IsOnWhatsAppResponse, error) {
 	jids := make([]types.JID, len(phones))
 	for i := range jids {
 		jids[i] = types.NewJID(phones[i], types.LegacyUserServer)
 	}
-	list, err := cli.usync(context.TODO(), jids, "query", "interactive", []waBinary.Node{
+	list, err := cli.usync(ctx, jids, "query", "interactive", []waBinary.Node{
 		{Tag: "business", Content: []waBinary.Node{{Tag: "verified_name"}}},
 		{Tag: "contact"},
 	})
@@ -200,7 +208,12 @@ func (cli *Client) IsOnWhatsApp(phones []string) ([]types.IsOnWhatsAppResponse,
 
 // GetUserInfo gets basic user info (avatar, status, verified business name, device list).
 func (cli *Client) GetUserInfo(jids []types.JID) (map[types.JID]types.UserInfo, error) {
-	list, err := cli.usync(context.TODO(), jids, "full", "background", []waBinary.Node{
+	return cli.GetUserInfoContext(context.TODO(), jids)
+}
+
+// GetUserInfoContext is like GetUserInfo, but the given context can be used to cancel the query.
+func (cli *Client) GetUserInfoContext(ctx context.Context, jids []types.JID) (map[types.JID]types.UserInfo, error) {
+	list, err := cli.usync(ctx, jids, "full", "background", []waBinary.Node{
 		{Tag: "business", Content: []waBinary.Node{{Tag: "verified_name"}}},
 		{Tag: "status"},
 		{Tag: "picture"},
@@ -220,12 +233,15 @@ func (cli *Client) GetUserInfo(jids []types.JID) (map[types.JID]types.UserInfo,
 		if err != nil {
 			cli.Log.Warnf("Failed to parse %s's verified name details: %v", jid, err)
 		}
-		status, _ := child.GetChildByTag("status").Content.([]byte)
+		statusNode := child.GetChildByTag("status")
+		status, _ := statusNode.Content.([]byte)
 		info.Status = string(status)
+		info.StatusSetAt = statusNode.AttrGetter().OptionalUnixTime("t")
+		cli.updateUserAbout(jid, types.UserAbout{Status: info.Status, SetAt: info.StatusSetAt}, false)
 		info.PictureID, _ = child.GetChildByTag("picture").Attrs["id"].(string)
 		info.Devices = parseDeviceList(jid, child.GetChildByTag("devices"))
 		if verifiedName != nil {
-			cli.updateBusinessName(context.TODO(), jid, nil, verifiedName.Details.GetVerifiedName())
+			cli.updateBusinessName(ctx, jid, nil, verifiedName.Details.GetVerifiedName())
 		}
 		respData[jid] = info
 	}
@@ -430,22 +446,47 @@ func (cli *Client) GetUserDevices(jids []types.JID) ([]types.JID, error) {
 }
 
 func (cli *Client) GetUserDevicesContext(ctx context.Context, jids []types.JID) ([]types.JID, error) {
+	return cli.GetUserDevicesWithParams(ctx, jids, nil)
+}
+
+// GetUserDevicesParams contains the optional parameters for GetUserDevicesWithParams.
+type GetUserDevicesParams struct {
+	// If true, device lists are always fetched from the server, even if they're cached.
+	BypassCache bool
+	// If true, only cached device lists are returned and nothing is fetched from the server.
+	// Users whose device lists aren't cached are left out of the result.
+	CacheOnly bool
+	// If set, cached device lists that were fetched longer ago than this are fetched again.
+	MaxStaleness time.Duration
+}
+
+// GetUserDevicesWithParams gets the list of devices that the given users have,
+// with options to control how the local device list cache is used.
+func (cli *Client) GetUserDevicesWithParams(ctx context.Context, jids []types.JID, params *GetUserDevicesParams) ([]types.JID, error) {
 	if cli == nil {
 		return nil, ErrClientIsNil
 	}
+	if params == nil {
+		params = &GetUserDevicesParams{}
+	}
 	cli.userDevicesCacheLock.Lock()
 	defer cli.userDevicesCacheLock.Unlock()
 
 	var devices, jidsToSync, fbJIDsToSync []types.JID
 	for _, jid := range jids {
 		cached, ok := cli.userDevicesCache[jid]
+		if params.BypassCache || (params.MaxStaleness > 0 && time.Since(cached.fetchedAt) > params.MaxStaleness) {
+			ok = false
+		}
 		if ok && len(cached.devices) > 0 {
 			devices = append(devices, cached.devices...)
-		} else if jid.Server == types.MessengerServer {
-			fbJIDsToSync = append(fbJIDsToSync, jid)
 		} else if jid.IsBot() {
 			// Bot JIDs do not have devices, the usync query is empty
 			devices = append(devices, jid)
+		} else if params.CacheOnly {
+			continue
+		} else if jid.Server == types.MessengerServer {
+			fbJIDsToSync = append(fbJIDsToSync, jid)
 		} else {
 			jidsToSync = append(jidsToSync, jid)
 		}
@@ -464,7 +505,7 @@ func (cli *Client) GetUserDevicesContext(ctx context.Context, jids []types.JID)
 				continue
 			}
 			userDevices := parseDeviceList(jid, user.GetChildByTag("devices"))
-			cli.userDevicesCache[jid] = deviceCache{devices: userDevices, dhash: participantListHashV2(userDevices)}
+			cli.userDevicesCache[jid] = deviceCache{devices: userDevices, dhash: participantListHashV2(userDevices), fetchedAt: time.Now()}
 			devices = append(devices, userDevices...)
 		}
 	}
@@ -480,9 +521,112 @@ func (cli *Client) GetUserDevicesContext(ctx context.Context, jids []types.JID)
 	return devices, nil
 }
 
+// GetUserAbout gets the about text of the given users and the time when it was set.
+//
+// If the about text of a user has changed since the last time it was fetched, an events.UserAbout event is dispatched.
+func (cli *Client) GetUserAbout(ctx context.Context, jids []types.JID) (map[types.JID]types.UserAbout, error) {
+	list, err := cli.usync(ctx, jids, "query", "background", []waBinary.Node{{Tag: "status"}})
+	if err != nil {
+		return nil, err
+	}
+	respData := make(map[types.JID]types.UserAbout, len(jids))
+	for _, child := range list.GetChildren() {
+		jid, jidOK := child.Attrs["jid"].(types.JID)
+		statusNode, statusOK := child.GetOptionalChildByTag("status")
+		if child.Tag != "user" || !jidOK || !statusOK {
+			continue
+		}
+		status, _ := statusNode.Content.([]byte)
+		about := types.UserAbout{
+			Status: string(status),
+			SetAt:  statusNode.AttrGetter().OptionalUnixTime("t"),
+		}
+		cli.updateUserAbout(jid, about, false)
+		respData[jid] = about
+	}
+	return respData, nil
+}
+
+// userAboutCacheSize is the maximum number of users whose about text is remembered for detecting changes.
+const userAboutCacheSize = 1024
+
+// updateUserAbout stores the given about text in the in-memory cache and dispatches an events.UserAbout
+// if the user's about text was previously known and has changed (or if alwaysDispatch is true).
+//
+// The cache only holds the most recently added userAboutCacheSize users, older entries are evicted.
+func (cli *Client) updateUserAbout(jid types.JID, about types.UserAbout, alwaysDispatch bool) {
+	cli.userAboutCacheLock.Lock()
+	prev, known := cli.userAboutCache[jid]
+	if !known {
+		if !cli.userAboutCacheList[cli.userAboutCachePtr].IsEmpty() {
+			delete(cli.userAboutCache, cli.userAboutCacheList[cli.userAboutCachePtr])
+		}
+		cli.userAboutCacheList[cli.userAboutCachePtr] = jid
+		cli.userAboutCachePtr++
+		if cli.userAboutCachePtr >= len(cli.userAboutCacheList) {
+			cli.userAboutCachePtr = 0
+		}
+	}
+	cli.userAboutCache[jid] = about
+	cli.userAboutCacheLock.Unlock()
+	if alwaysDispatch || (known && (prev.Status != about.Status || !prev.SetAt.Equal(about.SetAt))) {
+		cli.dispatchEvent(&events.UserAbout{
+			JID:       jid,
+			Status:    about.Status,
+			Timestamp: about.SetAt,
+		})
+	}
+}
+
+// GetLinkedDevices fetches the list of devices linked to the current account, including the primary device.
+//
+// WhatsApp doesn't reveal the names or activity of other devices to linked devices,
+// so only the device JIDs and key indexes are available.
+func (cli *Client) GetLinkedDevices(ctx context.Context) ([]types.LinkedDevice, error) {
+	if cli == nil {
+		return nil, ErrClientIsNil
+	}
+	ownID := cli.getOwnID()
+	if ownID.IsEmpty() {
+		return nil, ErrNotLoggedIn
+	}
+	list, err := cli.usync(ctx, []types.JID{ownID.ToNonAD()}, "query", "message", []waBinary.Node{
+		{Tag: "devices", Attrs: waBinary.Attrs{"version": "2"}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	var devices []types.LinkedDevice
+	for _, user := range list.GetChildren() {
+		jid, jidOK := user.Attrs["jid"].(types.JID)
+		if user.Tag != "user" || !jidOK {
+			continue
+		}
+		deviceList := user.GetChildByTag("devices", "device-list")
+		for _, device := range deviceList.GetChildren() {
+			ag := device.AttrGetter()
+			deviceID, ok := ag.GetInt64("id", true)
+			if device.Tag != "device" || !ok {
+				continue
+			}
+			jid.Device = uint16(deviceID)
+			devices = append(devices, types.LinkedDevice{
+				JID:       jid,
+				KeyIndex:  uint32(ag.OptionalInt("key-index")),
+				IsCurrent: jid.Device == ownID.Device,
+			})
+		}
+	}
+	return devices, nil
+}
+
+// GetProfilePictureParams contains the optional parameters for GetProfilePictureInfo.
 type GetProfilePictureParams struct {
-	Preview     bool
-	ExistingID  string
+	// If true, a low-resolution preview is requested instead of the full-resolution picture.
+	Preview bool
+	// The last known picture ID. If the picture hasn't changed, GetProfilePictureInfo will return nil.
+	ExistingID string
+	// Must be set when getting the photo of a community (parent group), which uses a different query.
 	IsCommunity bool
 }
 
@@ -493,6 +637,11 @@ type GetProfilePictureParams struct {
 //
 // To get a community photo, you should pass `IsCommunity: true`, as otherwise you may get a 401 error.
 func (cli *Client) GetProfilePictureInfo(jid types.JID, params *GetProfilePictureParams) (*types.ProfilePictureInfo, error) {
+	return cli.GetProfilePictureInfoContext(context.TODO(), jid, params)
+}
+
+// GetProfilePictureInfoContext is like GetProfilePictureInfo, but the given context can be used to cancel the query.
+func (cli *Client) GetProfilePictureInfoContext(ctx context.Context, jid types.JID, params *GetProfilePictureParams) (*types.ProfilePictureInfo, error) {
 	attrs := waBinary.Attrs{
 		"query": "url",
 	}
@@ -533,6 +682,7 @@ func (cli *Client) GetProfilePictureInfo(jid types.JID, params *GetProfilePictur
 		}}
 	}
 	resp, err := cli.sendIQ(infoQuery{
+		Context:   ctx,
 		Namespace: namespace,
 		Type:      "get",
 		To:        to,
@@ -578,24 +728,71 @@ func (cli *Client) GetProfilePictureInfo(jid types.JID, params *GetProfilePictur
 	return &info, nil
 }
 
+// ProfilePictureResult contains the result of a single query in GetProfilePictureInfos.
+type ProfilePictureResult struct {
+	Info  *types.ProfilePictureInfo
+	Error error
+}
+
+const defaultProfilePictureConcurrency = 10
+
+// GetProfilePictureInfos gets the profile pictures of many users or groups at once,
+// running up to the given number of queries concurrently (10 if concurrency is zero or negative).
+//
+// The given params are used for every query. The returned map contains one result for every given JID.
+// If the context is canceled, the remaining queries will fail with the context error.
+func (cli *Client) GetProfilePictureInfos(ctx context.Context, jids []types.JID, params *GetProfilePictureParams, concurrency int) map[types.JID]*ProfilePictureResult {
+	if concurrency <= 0 {
+		concurrency = defaultProfilePictureConcurrency
+	}
+	results := make(map[types.JID]*ProfilePictureResult, len(jids))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, concurrency)
+	for _, jid := range jids {
+		if _, alreadyQueued := results[jid]; alreadyQueued {
+			continue
+		}
+		result := &ProfilePictureResult{}
+		results[jid] = result
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			result.Error = ctx.Err()
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			result.Info, result.Error = cli.GetProfilePictureInfoContext(ctx, jid, params)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
 func (cli *Client) handleHistoricalPushNames(ctx context.Context, names []*waHistorySync.Pushname) {
 	if cli.Store.Contacts == nil {
 		return
 	}
 	cli.Log.Infof("Updating contact store with %d push names from history sync", len(names))
+	entries := make([]store.PushNameEntry, 0, len(names))
 	for _, user := range names {
 		if user.GetPushname() == "-" {
 			continue
 		}
-		var changed bool
 		if jid, err := types.ParseJID(user.GetID()); err != nil {
 			cli.Log.Warnf("Failed to parse user ID '%s' in push name history sync: %v", user.GetID(), err)
-		} else if changed, _, err = cli.Store.Contacts.PutPushName(ctx, jid, user.GetPushname()); err != nil {
-			cli.Log.Warnf("Failed to store push name of %s from history sync: %v", jid, err)
-		} else if changed {
-			cli.Log.Debugf("Got push name %s for %s in history sync", user.GetPushname(), jid)
+		} else {
+			entries = append(entries, store.PushNameEntry{JID: jid, PushName: user.GetPushname()})
 		}
 	}
+	err := cli.Store.Contacts.PutAllPushNames(ctx, entries)
+	if err != nil {
+		cli.Log.Warnf("Failed to store push names from history sync: %v", err)
+	}
 }
 
 func (cli *Client) updatePushName(ctx context.Context, user types.JID, messageInfo *types.MessageInfo, name string) {
@@ -700,8 +897,9 @@ func parseFBDeviceList(user types.JID, deviceList waBinary.Node) deviceCache {
 	}
 	// TODO do something with the icdc blob?
 	return deviceCache{
-		devices: devices,
-		dhash:   deviceList.AttrGetter().String("dhash"),
+		devices:   devices,
+		dhash:     deviceList.AttrGetter().String("dhash"),
+		fetchedAt: time.Now(),
 	}
 }
 
@@ -0,0 +1,219 @@
+// Copyright (c) 2025 Tulir Asokan
+//
+// This Source Code Form is subject to the terms of the Mozilla Public
+// License, v. 2.0. If a copy of the MPL was not distributed with this
+// file, You can obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package webhook contains an event handler that forwards whatsmeow events to HTTP webhooks as JSON.
+//
+//	dispatcher := webhook.NewDispatcher(webhook.Config{
+//		URLs:   []string{"https://example.com/whatsapp-events"},
+//		Secret: []byte("hunter2"),
+//	}, log)
+//	cli.AddEventHandler(dispatcher.HandleEvent)
+//	...
+//	dispatcher.Close()
+//
+// Each request body is a JSON object containing the event type, the time it was dispatched and the event itself.
+// If a secret is configured, the body is signed with HMAC-SHA256 and the hex signature is sent in the
+// X-Whatsmeow-Signature header as "sha256=<signature>".
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	waLog "go.mau.fi/whatsmeow/util/log"
+)
+
+// SignatureHeader is the HTTP header that contains the HMAC-SHA256 signature of the request body.
+const SignatureHeader = "X-Whatsmeow-Signature"
+
+// Config contains the settings for a Dispatcher.
+type Config struct {
+	// The URLs to POST events to. Every event is sent to every URL.
+	URLs []string
+	// If set, request bodies are signed with HMAC-SHA256 using this secret.
+	Secret []byte
+	// The HTTP client to use for requests. Defaults to a client with a 30 second timeout.
+	HTTPClient *http.Client
+
+	// The maximum number of retries after a failed request. Defaults to 5.
+	MaxRetries int
+	// The delay before the first retry, which is doubled after every attempt. Defaults to 1 second.
+	RetryDelay time.Duration
+	// The number of events that can be queued before HandleEvent starts blocking. Defaults to 1000.
+	QueueSize int
+
+	// If set, only events for which the filter returns true are sent.
+	Filter func(evt any) bool
+}
+
+// Payload is the JSON body sent to webhooks.
+type Payload struct {
+	Type      string    `json:"type"`
+	Timestamp time.Time `json:"timestamp"`
+	Event     any       `json:"event"`
+}
+
+// Dispatcher sends events to webhooks in the background.
+type Dispatcher struct {
+	config Config
+	log    waLog.Logger
+
+	queue     chan *Payload
+	stop      chan struct{}
+	stopOnce  sync.Once
+	workersWG sync.WaitGroup
+}
+
+// NewDispatcher creates a new webhook dispatcher and starts the background worker for delivering events.
+func NewDispatcher(config Config, log waLog.Logger) *Dispatcher {
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	if config.MaxRetries == 0 {
+		config.MaxRetries = 5
+	}
+	if config.RetryDelay == 0 {
+		config.RetryDelay = 1 * time.Second
+	}
+	if config.QueueSize == 0 {
+		config.QueueSize = 1000
+	}
+	if log == nil {
+		log = waLog.Noop
+	}
+	d := &Dispatcher{
+		config: config,
+		log:    log,
+		queue:  make(chan *Payload, config.QueueSize),
+		stop:   make(chan struct{}),
+	}
+	d.workersWG.Add(1)
+	go d.loop()
+	return d
+}
+
+// HandleEvent queues the given event to be sent to the webhooks. It can be passed directly to Client.AddEventHandler.
+func (d *Dispatcher) HandleEvent(evt any) {
+	if d.config.Filter != nil && !d.config.Filter(evt) {
+		return
+	}
+	payload := &Payload{
+		Type:      EventType(evt),
+		Timestamp: time.Now(),
+		Event:     evt,
+	}
+	select {
+	case d.queue <- payload:
+	case <-d.stop:
+	}
+}
+
+// Close stops the dispatcher. Events that are still queued will be dropped.
+func (d *Dispatcher) Close() {
+	d.stopOnce.Do(func() {
+		close(d.stop)
+	})
+	d.workersWG.Wait()
+}
+
+// EventType returns the name of the type of the given event, e.g. "Message" for *events.Message.
+func EventType(evt any) string {
+	t := reflect.TypeOf(evt)
+	if t == nil {
+		return ""
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+// Sign returns the hex-encoded HMAC-SHA256 signature of the given body.
+func Sign(secret, body []byte) string {
+	h := hmac.New(sha256.New, secret)
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func (d *Dispatcher) loop() {
+	defer d.workersWG.Done()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-d.stop
+		cancel()
+	}()
+	for {
+		select {
+		case payload := <-d.queue:
+			d.deliver(ctx, payload)
+		case <-d.stop:
+			return
+		}
+	}
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, payload *Payload) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		d.log.Errorf("Failed to marshal %s event for webhook: %v", payload.Type, err)
+		return
+	}
+	for _, url := range d.config.URLs {
+		err = d.sendWithRetries(ctx, url, body)
+		if err != nil {
+			d.log.Errorf("Failed to send %s event to webhook %s: %v", payload.Type, url, err)
+		}
+	}
+}
+
+func (d *Dispatcher) sendWithRetries(ctx context.Context, url string, body []byte) error {
+	delay := d.config.RetryDelay
+	for attempt := 0; ; attempt++ {
+		err := d.send(ctx, url, body)
+		if err == nil || attempt >= d.config.MaxRetries {
+			return err
+		}
+		d.log.Warnf("Failed to send event to webhook %s (attempt #%d): %v, retrying in %s", url, attempt+1, err, delay)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		delay *= 2
+	}
+}
+
+func (d *Dispatcher) send(ctx context.Context, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to prepare request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(d.config.Secret) > 0 {
+		req.Header.Set(SignatureHeader, "sha256="+Sign(d.config.Secret, body))
+	}
+	resp, err := d.config.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+	return nil
+}